@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// Button styles accepted in the action catalog
+	ActionStylePrimary = "primary"
+	ActionStyleDanger  = "danger"
+
+	// DefaultActionGroup is the dialog section for actions without a group
+	DefaultActionGroup = "Actions"
+
+	// CommandPlaceholderLogLines is replaced with the docker compose logs line limit
+	CommandPlaceholderLogLines = "{log_lines}"
+)
+
+// Action is a docker compose action that can be triggered from Slack
+type Action struct {
+	ID          string `json:"id"`                    // Block Kit action ID of the dialog button
+	Name        string `json:"name"`                  // Short name, also used as the button value
+	Emoji       string `json:"emoji,omitempty"`       // Reaction that triggers the action; none when empty
+	Label       string `json:"label"`                 // Button text
+	Style       string `json:"style,omitempty"`       // Button style: primary, danger or empty
+	Group       string `json:"group,omitempty"`       // Dialog section the button is shown in
+	Command     string `json:"command"`               // Command template, see expandCommand
+	Destructive bool   `json:"destructive,omitempty"` // Whether the action must be confirmed before running
+	Role        Role   `json:"role,omitempty"`        // Minimum role; derived from the command when empty
+}
+
+// DefaultActions is the action catalog used when no actions file is configured
+var DefaultActions = []Action{
+	{
+		ID:      ActionDockerUp,
+		Name:    "up",
+		Emoji:   EmojiUpArrow,
+		Label:   ":arrow_up: Up",
+		Style:   ActionStylePrimary,
+		Group:   "Lifecycle Actions",
+		Command: "docker compose up -d",
+	},
+	{
+		ID:      ActionDockerRestart,
+		Name:    "restart",
+		Emoji:   EmojiArrowsCounterClockwise,
+		Label:   ":arrows_counterclockwise: Restart",
+		Group:   "Lifecycle Actions",
+		Command: "docker compose restart",
+	},
+	{
+		ID:          ActionDockerDown,
+		Name:        "down",
+		Emoji:       EmojiDownArrow,
+		Label:       ":arrow_down: Down",
+		Style:       ActionStyleDanger,
+		Group:       "Lifecycle Actions",
+		Command:     "docker compose down",
+		Destructive: true,
+	},
+	{
+		ID:      ActionDockerPS,
+		Name:    "ps",
+		Label:   ":chart_with_upwards_trend: Process Status",
+		Group:   "Observation",
+		Command: "docker compose ps",
+	},
+	{
+		ID:      ActionDockerLogs,
+		Name:    "logs",
+		Emoji:   EmojiPageFacingUp,
+		Label:   ":page_facing_up: View Logs",
+		Group:   "Observation",
+		Command: "docker compose logs -n " + CommandPlaceholderLogLines,
+	},
+}
+
+// RequiredRole returns the minimum role allowed to run the action
+func (a Action) RequiredRole() Role {
+	if a.Role != "" {
+		return a.Role
+	}
+	return requiredRole(a.Command)
+}
+
+// actions returns the configured action catalog, falling back to the default catalog
+func (c *Config) actions() []Action {
+	if c.Actions == nil {
+		return DefaultActions
+	}
+	return c.Actions
+}
+
+// ActionByEmoji returns the action triggered by an emoji reaction
+func (c *Config) ActionByEmoji(emoji string) (Action, bool) {
+	for _, action := range c.actions() {
+		if action.Emoji != "" && action.Emoji == emoji {
+			return action, true
+		}
+	}
+	return Action{}, false
+}
+
+// ActionByID returns the action with the given Block Kit action ID
+func (c *Config) ActionByID(id string) (Action, bool) {
+	for _, action := range c.actions() {
+		if action.ID == id {
+			return action, true
+		}
+	}
+	return Action{}, false
+}
+
+// ActionByName returns the action with the given name
+func (c *Config) ActionByName(name string) (Action, bool) {
+	for _, action := range c.actions() {
+		if action.Name == name {
+			return action, true
+		}
+	}
+	return Action{}, false
+}
+
+// loadActionConfig loads the action catalog from a JSON file, keeping the default catalog if the file doesn't exist
+func (c *Config) loadActionConfig() error {
+	if _, err := os.Stat(c.ActionsConfigPath); os.IsNotExist(err) {
+		c.Actions = DefaultActions
+		return nil
+	}
+
+	data, err := os.ReadFile(c.ActionsConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read action config file: %w", err)
+	}
+
+	var actions []Action
+	if err := json.Unmarshal(data, &actions); err != nil {
+		return fmt.Errorf("failed to parse action config: %w", err)
+	}
+
+	if err := validateActions(actions); err != nil {
+		return err
+	}
+
+	c.Actions = actions
+	return nil
+}
+
+// validateActions checks that every action is complete and that IDs, names and emojis are unique
+func validateActions(actions []Action) error {
+	if len(actions) == 0 {
+		return fmt.Errorf("action catalog is empty")
+	}
+
+	ids := make(map[string]bool)
+	names := make(map[string]bool)
+	emojis := make(map[string]bool)
+
+	for i, action := range actions {
+		switch {
+		case action.ID == "":
+			return fmt.Errorf("action %d: id is required", i)
+		case action.Name == "" || strings.ContainsAny(action.Name, " \t"):
+			return fmt.Errorf("action %q: name is required and must not contain spaces", action.ID)
+		case action.Label == "":
+			return fmt.Errorf("action %q: label is required", action.ID)
+		case action.Command == "":
+			return fmt.Errorf("action %q: command is required", action.ID)
+		case action.Style != "" && action.Style != ActionStylePrimary && action.Style != ActionStyleDanger:
+			return fmt.Errorf("action %q: unknown style %q (expected primary or danger)", action.ID, action.Style)
+		case action.Role != "" && !action.Role.Valid():
+			return fmt.Errorf("action %q: unknown role %q", action.ID, action.Role)
+		case action.Emoji == EmojiWhiteCheckMark:
+			return fmt.Errorf("action %q: emoji %q is reserved for confirmations", action.ID, action.Emoji)
+		}
+
+		if ids[action.ID] {
+			return fmt.Errorf("duplicate action id %q", action.ID)
+		}
+		if names[action.Name] {
+			return fmt.Errorf("duplicate action name %q", action.Name)
+		}
+		if action.Emoji != "" && emojis[action.Emoji] {
+			return fmt.Errorf("duplicate action emoji %q", action.Emoji)
+		}
+		ids[action.ID] = true
+		names[action.Name] = true
+		if action.Emoji != "" {
+			emojis[action.Emoji] = true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadActionConfig_ValidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "actions.json")
+	data := `[
+		{"id": "docker_pull", "name": "pull", "emoji": "arrow_double_down", "label": "Pull", "command": "docker compose pull"},
+		{"id": "docker_stop", "name": "stop", "label": "Stop", "style": "danger", "command": "docker compose stop", "destructive": true, "role": "operator"}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{ActionsConfigPath: path}
+	if err := cfg.loadActionConfig(); err != nil {
+		t.Fatalf("loadActionConfig() error = %v", err)
+	}
+	if len(cfg.Actions) != 2 {
+		t.Fatalf("expected 2 actions, got %d", len(cfg.Actions))
+	}
+
+	pull, ok := cfg.ActionByEmoji("arrow_double_down")
+	if !ok || pull.Name != "pull" {
+		t.Errorf("ActionByEmoji(arrow_double_down) = %+v, %v", pull, ok)
+	}
+	stop, ok := cfg.ActionByID("docker_stop")
+	if !ok || !stop.Destructive || stop.RequiredRole() != RoleOperator {
+		t.Errorf("ActionByID(docker_stop) = %+v, %v", stop, ok)
+	}
+	if _, ok := cfg.ActionByName("up"); ok {
+		t.Error("built-in actions should be replaced by the file")
+	}
+}
+
+func TestLoadActionConfig_MissingFile(t *testing.T) {
+	cfg := &Config{ActionsConfigPath: filepath.Join(t.TempDir(), "missing.json")}
+	if err := cfg.loadActionConfig(); err != nil {
+		t.Fatalf("loadActionConfig() error = %v", err)
+	}
+	if len(cfg.Actions) != len(DefaultActions) {
+		t.Errorf("expected the default catalog, got %d actions", len(cfg.Actions))
+	}
+}
+
+func TestLoadActionConfig_InvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "actions.json")
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{ActionsConfigPath: path}
+	if err := cfg.loadActionConfig(); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestValidateActions(t *testing.T) {
+	valid := Action{ID: "docker_pull", Name: "pull", Label: "Pull", Command: "docker compose pull"}
+
+	tests := []struct {
+		name    string
+		actions []Action
+		wantErr bool
+	}{
+		{"default catalog", DefaultActions, false},
+		{"empty catalog", []Action{}, true},
+		{"missing id", []Action{{Name: "pull", Label: "Pull", Command: "docker compose pull"}}, true},
+		{"name with spaces", []Action{{ID: "x", Name: "pull all", Label: "Pull", Command: "docker compose pull"}}, true},
+		{"missing label", []Action{{ID: "x", Name: "pull", Command: "docker compose pull"}}, true},
+		{"missing command", []Action{{ID: "x", Name: "pull", Label: "Pull"}}, true},
+		{"unknown style", []Action{{ID: "x", Name: "pull", Label: "Pull", Command: "docker compose pull", Style: "blue"}}, true},
+		{"unknown role", []Action{{ID: "x", Name: "pull", Label: "Pull", Command: "docker compose pull", Role: "root"}}, true},
+		{"reserved emoji", []Action{{ID: "x", Name: "pull", Label: "Pull", Command: "docker compose pull", Emoji: EmojiWhiteCheckMark}}, true},
+		{"duplicate id", []Action{valid, {ID: "docker_pull", Name: "pull2", Label: "Pull", Command: "docker compose pull"}}, true},
+		{"duplicate name", []Action{valid, {ID: "other", Name: "pull", Label: "Pull", Command: "docker compose pull"}}, true},
+		{"duplicate emoji", []Action{
+			{ID: "a", Name: "a", Label: "A", Command: "docker compose pull", Emoji: "star"},
+			{ID: "b", Name: "b", Label: "B", Command: "docker compose stop", Emoji: "star"},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateActions(tt.actions)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateActions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAction_RequiredRole(t *testing.T) {
+	if got := (Action{Command: "docker compose ps"}).RequiredRole(); got != RoleViewer {
+		t.Errorf("RequiredRole() = %q, want %q", got, RoleViewer)
+	}
+	if got := (Action{Command: "docker compose ps", Role: RoleAdmin}).RequiredRole(); got != RoleAdmin {
+		t.Errorf("RequiredRole() = %q, want %q", got, RoleAdmin)
+	}
+}
+
+func TestActionBlocks_GroupsCatalog(t *testing.T) {
+	svc := newTestService(nil, nil)
+	svc.config.Actions = []Action{
+		{ID: "a", Name: "a", Label: "A", Group: "First", Command: "docker compose ps"},
+		{ID: "b", Name: "b", Label: "B", Command: "docker compose ps"},
+		{ID: "c", Name: "c", Label: "C", Group: "First", Command: "docker compose ps"},
+	}
+
+	// Header and buttons for "First", then for the default group
+	if blocks := svc.actionBlocks(); len(blocks) != 4 {
+		t.Fatalf("expected 4 blocks, got %d", len(blocks))
+	}
+}
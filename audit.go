@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// Audit record events
+	AuditEventDispatch = "dispatch"
+	AuditEventOutcome  = "outcome"
+
+	// Audit record outcomes
+	AuditOutcomeDispatched = "dispatched"
+	AuditOutcomeFailed     = "failed"
+	AuditOutcomeCompleted  = "completed"
+
+	// Entry points that can trigger a command
+	SourceCommand  = "command"
+	SourceReaction = "reaction"
+	SourceButton   = "button"
+)
+
+// AuditRecord is a single entry in the audit log
+type AuditRecord struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Event       string    `json:"event"`
+	RequestID   string    `json:"request_id,omitempty"`
+	UserID      string    `json:"user_id,omitempty"`
+	Channel     string    `json:"channel,omitempty"`
+	Source      string    `json:"source,omitempty"`
+	Project     string    `json:"project"`
+	Command     string    `json:"command"`
+	Branch      string    `json:"branch,omitempty"`
+	Outcome     string    `json:"outcome"`
+	Error       string    `json:"error,omitempty"`
+	OutputBytes int       `json:"output_bytes,omitempty"`
+	StderrBytes int       `json:"stderr_bytes,omitempty"`
+	LatencyMS   int64     `json:"latency_ms,omitempty"`
+}
+
+// AuditSink persists audit records
+type AuditSink interface {
+	Record(ctx context.Context, record AuditRecord) error
+	Close() error
+}
+
+// NewAuditSink creates the audit sinks enabled in the configuration.
+// With no sinks configured the returned sink discards every record.
+func NewAuditSink(config *Config, redisClient RedisClientInterface) (AuditSink, error) {
+	var sinks multiAuditSink
+
+	if config.AuditLogPath != "" {
+		fileSink, err := NewFileAuditSink(config.AuditLogPath)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, fileSink)
+	}
+
+	if config.AuditStreamName != "" {
+		sinks = append(sinks, NewRedisStreamAuditSink(redisClient, config.AuditStreamName))
+	}
+
+	return sinks, nil
+}
+
+// FileAuditSink appends audit records to a file as JSON lines
+type FileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileAuditSink opens (or creates) an append-only JSONL audit file
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+	return &FileAuditSink{file: file}, nil
+}
+
+// Record appends a record to the file
+func (f *FileAuditSink) Record(ctx context.Context, record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	data = append(data, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the file
+func (f *FileAuditSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.file.Sync(); err != nil {
+		f.file.Close()
+		return fmt.Errorf("failed to sync audit log file: %w", err)
+	}
+	return f.file.Close()
+}
+
+// RedisStreamAuditSink appends audit records to a Redis stream
+type RedisStreamAuditSink struct {
+	redisClient RedisClientInterface
+	stream      string
+}
+
+// NewRedisStreamAuditSink creates a sink writing to the given Redis stream
+func NewRedisStreamAuditSink(redisClient RedisClientInterface, stream string) *RedisStreamAuditSink {
+	return &RedisStreamAuditSink{redisClient: redisClient, stream: stream}
+}
+
+// Record adds a record to the stream as a JSON-encoded "record" field
+func (r *RedisStreamAuditSink) Record(ctx context.Context, record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	if err := r.redisClient.XAdd(ctx, r.stream, map[string]interface{}{"record": data}); err != nil {
+		return fmt.Errorf("failed to add audit record to stream: %w", err)
+	}
+	return nil
+}
+
+// Close is a no-op; the Redis client is owned by the caller
+func (r *RedisStreamAuditSink) Close() error {
+	return nil
+}
+
+// multiAuditSink fans records out to several sinks
+type multiAuditSink []AuditSink
+
+// Record writes the record to every sink, returning the combined errors
+func (m multiAuditSink) Record(ctx context.Context, record AuditRecord) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Record(ctx, record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every sink, returning the combined errors
+func (m multiAuditSink) Close() error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// recordAudit writes an audit record, logging failures rather than interrupting the caller
+func (s *Service) recordAudit(ctx context.Context, record AuditRecord) {
+	if s.audit == nil {
+		return
+	}
+
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now().UTC()
+	}
+
+	if err := s.audit.Record(ctx, record); err != nil {
+		slog.Error("Failed to write audit record", "error", err, "event", record.Event, "project", record.Project)
+	}
+}
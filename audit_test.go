@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recordingAuditSink keeps audit records in memory
+type recordingAuditSink struct {
+	records []AuditRecord
+}
+
+func (r *recordingAuditSink) Record(ctx context.Context, record AuditRecord) error {
+	r.records = append(r.records, record)
+	return nil
+}
+
+func (r *recordingAuditSink) Close() error { return nil }
+
+func TestFileAuditSink_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for i := 0; i < 2; i++ {
+		sink, err := NewFileAuditSink(path)
+		if err != nil {
+			t.Fatalf("NewFileAuditSink() error = %v", err)
+		}
+		if err := sink.Record(context.Background(), AuditRecord{Event: AuditEventDispatch, Project: fmt.Sprintf("p%d", i)}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit file: %v", err)
+	}
+	defer f.Close()
+
+	var projects []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		projects = append(projects, record.Project)
+	}
+	if len(projects) != 2 || projects[0] != "p0" || projects[1] != "p1" {
+		t.Errorf("projects = %v, want [p0 p1]", projects)
+	}
+}
+
+func TestRedisStreamAuditSink_AddsRecord(t *testing.T) {
+	rc := &mockRedisClient{}
+	sink := NewRedisStreamAuditSink(rc, "slackcompose:audit")
+
+	if err := sink.Record(context.Background(), AuditRecord{Event: AuditEventDispatch, Project: "my-project"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	if len(rc.added) != 1 || rc.added[0].stream != "slackcompose:audit" {
+		t.Fatalf("expected 1 XAdd to slackcompose:audit, got %+v", rc.added)
+	}
+	var record AuditRecord
+	if err := json.Unmarshal(rc.added[0].values["record"].([]byte), &record); err != nil {
+		t.Fatalf("failed to unmarshal record: %v", err)
+	}
+	if record.Project != "my-project" {
+		t.Errorf("Project = %q, want %q", record.Project, "my-project")
+	}
+}
+
+func TestDispatch_RecordsAudit(t *testing.T) {
+	sink := &recordingAuditSink{}
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+	svc.audit = sink
+
+	payload := SlackCommand{Command: "/slack-compose", Text: "my-project", UserID: "U1", ChannelID: "C123"}
+	data, _ := json.Marshal(payload)
+	svc.handleCommand(context.Background(), string(data))
+
+	rc.pushErr = fmt.Errorf("redis down")
+	svc.handleCommand(context.Background(), string(data))
+
+	if len(sink.records) != 2 {
+		t.Fatalf("expected 2 audit records, got %d", len(sink.records))
+	}
+	got := sink.records[0]
+	if got.Event != AuditEventDispatch || got.Outcome != AuditOutcomeDispatched {
+		t.Errorf("record = %+v, want dispatched dispatch event", got)
+	}
+	if got.UserID != "U1" || got.Channel != "C123" || got.Source != SourceCommand || got.Project != "my-project" {
+		t.Errorf("record = %+v, missing request details", got)
+	}
+	if got.Timestamp.IsZero() {
+		t.Error("record timestamp should be set")
+	}
+	if failed := sink.records[1]; failed.Outcome != AuditOutcomeFailed || failed.Error == "" {
+		t.Errorf("record = %+v, want failed outcome with error", failed)
+	}
+}
+
+func TestHandlePoppitOutput_RecordsOutcome(t *testing.T) {
+	sink := &recordingAuditSink{}
+	svc := newTestService(nil, nil)
+	svc.audit = sink
+
+	out := PoppitCommandOutput{
+		Type:    "slack-compose",
+		Command: "docker compose down",
+		Output:  "Stopped",
+		Stderr:  "warn",
+		Metadata: map[string]interface{}{
+			"project": "my-project",
+			"user":    "U1",
+			"source":  SourceReaction,
+			"channel": "C123",
+		},
+	}
+	data, _ := json.Marshal(out)
+	svc.handlePoppitOutput(context.Background(), string(data))
+
+	if len(sink.records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(sink.records))
+	}
+	got := sink.records[0]
+	if got.Event != AuditEventOutcome || got.Outcome != AuditOutcomeCompleted {
+		t.Errorf("record = %+v, want completed outcome event", got)
+	}
+	if got.UserID != "U1" || got.Source != SourceReaction || got.Channel != "C123" {
+		t.Errorf("record = %+v, missing request details", got)
+	}
+	if got.OutputBytes != 7 || got.StderrBytes != 4 {
+		t.Errorf("byte counts = %d/%d, want 7/4", got.OutputBytes, got.StderrBytes)
+	}
+}
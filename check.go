@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// runCLI runs a command-line subcommand, reporting whether there was one to run.
+// Without arguments the service starts normally.
+func runCLI(args []string, stdout, stderr io.Writer) (exitCode int, handled bool) {
+	switch {
+	case len(args) == 0:
+		return 0, false
+	case len(args) >= 2 && args[0] == "config" && args[1] == "check":
+		return runConfigCheck(args[2:], stdout, stderr), true
+	case args[0] == "healthcheck":
+		return runHealthcheck(args[1:], stdout, stderr), true
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\nusage: slackcompose [config check [-check-dirs] [projects.json] | healthcheck [-path /readyz]]\n", strings.Join(args, " "))
+		return 2, true
+	}
+}
+
+// runConfigCheck validates a project config file, returning the process exit code:
+// 0 when the file is valid, 1 when it has problems and 2 for usage errors
+func runConfigCheck(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("config check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	checkDirs := flags.Bool("check-dirs", false, "also require each working_dir to exist on this host")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: slackcompose config check [-check-dirs] [projects.json]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	path := getEnv("PROJECT_CONFIG_PATH", "projects.json")
+	if flags.NArg() == 1 {
+		path = flags.Arg(0)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: failed to read project config file: %v\n", path, err)
+		return 1
+	}
+
+	projects, err := parseProjectConfig(path, data)
+	if err == nil {
+		err = validateProjects(projects, *checkDirs)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", path, err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "%s: OK (%d projects)\n", path, len(projects))
+	return 0
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateProjects_AggregatesProblems(t *testing.T) {
+	projects := []ProjectConfig{
+		{Name: "ok", WorkingDir: "/srv/ok"},
+		{Name: "", WorkingDir: "/srv/unnamed"},
+		{Name: "my project", WorkingDir: "/srv/spaces"},
+		{Name: "ok", WorkingDir: "/srv/duplicate"},
+		{Name: "relative", WorkingDir: "srv/relative"},
+		{Name: "nodir"},
+		{Name: "list", WorkingDir: "/srv/list"},
+		{Name: "badrole", WorkingDir: "/srv/badrole", Roles: map[string]Role{"U1": "root"}},
+	}
+
+	err := validateProjects(projects, false)
+	problems, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	if len(problems) != 7 {
+		t.Errorf("expected 7 problems, got %d:\n%v", len(problems), err)
+	}
+	for _, want := range []string{"name is required", "whitespace", "duplicate of project 1", "absolute path", "working_dir is required", "reserved", "unknown role"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("report should mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestValidateProjects_CheckDirs(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	os.WriteFile(file, nil, 0o600)
+
+	projects := []ProjectConfig{
+		{Name: "exists", WorkingDir: dir},
+		{Name: "missing", WorkingDir: filepath.Join(dir, "missing")},
+		{Name: "file", WorkingDir: file},
+	}
+
+	if err := validateProjects(projects, false); err != nil {
+		t.Errorf("directories should not be checked by default, got %v", err)
+	}
+	err := validateProjects(projects, true)
+	if problems, ok := err.(ValidationErrors); !ok || len(problems) != 2 {
+		t.Errorf("expected 2 problems, got %v", err)
+	}
+}
+
+func TestRunConfigCheck(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.json")
+	os.WriteFile(valid, []byte(`[{"name": "a", "working_dir": "/srv/a"}]`), 0o600)
+	invalid := filepath.Join(dir, "invalid.json")
+	os.WriteFile(invalid, []byte(`[{"name": "a", "working_dir": "/srv/a"}, {"name": "a", "working_dir": "relative"}]`), 0o600)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{"valid file", []string{valid}, 0, "OK (1 projects)"},
+		{"invalid file", []string{invalid}, 1, "2 configuration problem(s)"},
+		{"missing file", []string{filepath.Join(dir, "missing.json")}, 1, "failed to read"},
+		{"check dirs", []string{"-check-dirs", valid}, 1, "does not exist"},
+		{"too many arguments", []string{valid, invalid}, 2, "usage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runConfigCheck(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+			if output := stdout.String() + stderr.String(); !strings.Contains(output, tt.wantOut) {
+				t.Errorf("output %q should contain %q", output, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestRunCLI(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	if _, handled := runCLI(nil, &stdout, &stderr); handled {
+		t.Error("no arguments should start the service")
+	}
+	if code, handled := runCLI([]string{"frobnicate"}, &stdout, &stderr); !handled || code != 2 {
+		t.Errorf("unknown command: code = %d, handled = %v", code, handled)
+	}
+}
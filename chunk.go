@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultOutputChunkSize keeps each message under Slack's recommended 4000 characters
+	DefaultOutputChunkSize = 3500
+	// DefaultOutputMaxChunks caps the number of messages posted for one command's output
+	DefaultOutputMaxChunks = 5
+	// MinOutputChunkSize leaves room for the header, fences and the omission notice
+	MinOutputChunkSize = 500
+
+	codeFence = "```"
+
+	// omittedNoticeReserve is kept free in the last chunk for the omission notice
+	omittedNoticeReserve = 64
+	// truncatedLineMarker ends lines too long to fit in a single chunk
+	truncatedLineMarker = " …"
+)
+
+// outputSection is a titled block of command output rendered as a code block
+type outputSection struct {
+	Title string // Shown above the code block, e.g. "*Standard Error:*"; empty for none
+	Body  string
+}
+
+// outputLine is one line of a message, either plain text or inside a code block
+type outputLine struct {
+	text   string
+	fenced bool
+}
+
+// chunkOutput splits the header and sections into messages of at most size bytes.
+// Lines are never split and code blocks are closed and reopened across messages.
+// At most maxChunks messages are returned; the last one then says how many lines were omitted.
+func chunkOutput(header string, sections []outputSection, size, maxChunks int) []string {
+	if size <= 0 {
+		size = DefaultOutputChunkSize
+	}
+	if maxChunks <= 0 {
+		maxChunks = DefaultOutputMaxChunks
+	}
+
+	lines := []outputLine{{text: header}}
+	for _, section := range sections {
+		if section.Body == "" {
+			continue
+		}
+		if section.Title != "" {
+			lines = append(lines, outputLine{text: section.Title})
+		}
+		for _, line := range strings.Split(strings.TrimRight(section.Body, "\n"), "\n") {
+			lines = append(lines, outputLine{text: line, fenced: true})
+		}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	open := false // current chunk has an unclosed code block
+
+	flush := func() {
+		if open {
+			current.WriteString("\n" + codeFence)
+			open = false
+		}
+		chunks = append(chunks, current.String())
+		current.Reset()
+	}
+
+	for i, line := range lines {
+		// The last allowed chunk keeps room to say what was left out
+		budget := size
+		if len(chunks) == maxChunks-1 {
+			budget -= omittedNoticeReserve
+		}
+
+		text := fitLine(line.text, size)
+		if current.Len() > 0 && current.Len()+lineCost(text, line.fenced, open) > budget {
+			flush()
+			if len(chunks) == maxChunks {
+				chunks[len(chunks)-1] += fmt.Sprintf("\n_… %d more lines omitted_", countFenced(lines[i:]))
+				return chunks
+			}
+		}
+
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		if line.fenced != open {
+			current.WriteString(codeFence + "\n")
+			open = line.fenced
+		}
+		current.WriteString(text)
+	}
+	flush()
+	return chunks
+}
+
+// lineCost is the number of bytes adding the line takes, including opening or
+// closing a code block and the fence needed to close it again at the end of the chunk
+func lineCost(text string, fenced, open bool) int {
+	cost := len("\n") + len(text)
+	if fenced != open {
+		cost += len(codeFence + "\n")
+	}
+	if fenced {
+		cost += len("\n" + codeFence)
+	}
+	return cost
+}
+
+// countFenced counts the lines of command output, leaving out headers and titles
+func countFenced(lines []outputLine) int {
+	count := 0
+	for _, line := range lines {
+		if line.fenced {
+			count++
+		}
+	}
+	return count
+}
+
+// fitLine truncates a line that could not fit in a chunk of its own
+func fitLine(text string, size int) string {
+	limit := size - omittedNoticeReserve - 2*len(codeFence+"\n") - len(truncatedLineMarker)
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	// Don't leave half of an escaped &amp;, &lt; or &gt; behind
+	if amp := strings.LastIndexByte(text[:cut], '&'); amp >= 0 && cut-amp < len("&amp;") && !strings.Contains(text[amp:cut], ";") {
+		cut = amp
+	}
+	return text[:cut] + truncatedLineMarker
+}
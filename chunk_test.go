@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns n output lines of the form "line 0001 ..."
+func numberedLines(n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %04d %s", i+1, strings.Repeat("x", 40))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestChunkOutput_SingleMessage(t *testing.T) {
+	chunks := chunkOutput("*Project:* p", []outputSection{
+		{Body: "a\nb\n"},
+		{Title: "*Standard Error:*", Body: "oops"},
+	}, 1000, 3)
+
+	want := "*Project:* p\n```\na\nb\n```\n*Standard Error:*\n```\noops\n```"
+	if len(chunks) != 1 || chunks[0] != want {
+		t.Errorf("chunks = %q, want [%q]", chunks, want)
+	}
+}
+
+func TestChunkOutput_EmptySectionsSkipped(t *testing.T) {
+	chunks := chunkOutput("*Project:* p", []outputSection{{Body: ""}, {Title: "*Standard Error:*", Body: ""}}, 1000, 3)
+	if len(chunks) != 1 || chunks[0] != "*Project:* p" {
+		t.Errorf("chunks = %q", chunks)
+	}
+}
+
+func TestChunkOutput_SplitsOnLinesAndFences(t *testing.T) {
+	output := numberedLines(100)
+	chunks := chunkOutput("*Project:* p", []outputSection{{Body: output}}, 600, 50)
+
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+
+	var got []string
+	for i, chunk := range chunks {
+		if len(chunk) > 600 {
+			t.Errorf("chunk %d is %d bytes, over the limit", i, len(chunk))
+		}
+		if strings.Count(chunk, codeFence)%2 != 0 {
+			t.Errorf("chunk %d has an unbalanced code fence:\n%s", i, chunk)
+		}
+		for _, line := range strings.Split(chunk, "\n") {
+			if strings.HasPrefix(line, "line ") {
+				got = append(got, line)
+			}
+		}
+	}
+	// Every line arrives whole and in order
+	if strings.Join(got, "\n") != output {
+		t.Error("chunks do not reassemble into the original output")
+	}
+}
+
+func TestChunkOutput_CapsChunks(t *testing.T) {
+	chunks := chunkOutput("*Project:* p", []outputSection{{Body: numberedLines(200)}}, 600, 3)
+
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	last := chunks[2]
+	if len(last) > 600 {
+		t.Errorf("last chunk is %d bytes, over the limit", len(last))
+	}
+
+	// The notice counts exactly the lines that were not sent
+	sent := 0
+	for _, chunk := range chunks {
+		sent += strings.Count(chunk, "line ")
+	}
+	if want := fmt.Sprintf("_… %d more lines omitted_", 200-sent); !strings.HasSuffix(last, want) {
+		t.Errorf("last chunk should end with %q, got %q", want, last[len(last)-40:])
+	}
+}
+
+func TestChunkOutput_TruncatesOverlongLines(t *testing.T) {
+	chunks := chunkOutput("h", []outputSection{{Body: strings.Repeat("é", 1000)}}, 600, 5)
+
+	for i, chunk := range chunks {
+		if len(chunk) > 600 {
+			t.Errorf("chunk %d is %d bytes, over the limit", i, len(chunk))
+		}
+	}
+	if !strings.Contains(chunks[len(chunks)-1], truncatedLineMarker+"\n"+codeFence) {
+		t.Errorf("overlong line should be truncated with a marker, got %q", chunks)
+	}
+}
+
+func TestHandlePoppitOutput_ChunksLongOutput(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+	svc.config.OutputChunkSize = 600
+	svc.config.OutputMaxChunks = 10
+
+	data, _ := json.Marshal(PoppitCommandOutput{
+		Type:     "slack-compose",
+		Command:  "docker compose logs -n 100",
+		Output:   numberedLines(40),
+		Metadata: map[string]interface{}{"project": "my-project", "thread_ts": "123.456"},
+	})
+	svc.handlePoppitOutput(context.Background(), string(data))
+
+	if len(rc.pushed) < 2 {
+		t.Fatalf("expected the output to be split over several messages, got %d", len(rc.pushed))
+	}
+	for i, push := range rc.pushed {
+		var slp SlackLinerPayload
+		json.Unmarshal(push.value.([]byte), &slp)
+		if slp.ThreadTS != "123.456" {
+			t.Errorf("message %d thread_ts = %q, want %q", i, slp.ThreadTS, "123.456")
+		}
+		if slp.Metadata.EventPayload["project"] != "my-project" {
+			t.Errorf("message %d should carry the project metadata", i)
+		}
+	}
+}
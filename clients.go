@@ -14,6 +14,7 @@ func (s *Service) sendToPoppit(ctx context.Context, payload PoppitPayload) error
 	}
 
 	if err := s.redisClient.RPush(ctx, s.config.PoppitListName, data); err != nil {
+		s.metrics.rpushFailed(s.config.PoppitListName)
 		return fmt.Errorf("failed to push to Redis list: %w", err)
 	}
 
@@ -28,8 +29,10 @@ func (s *Service) sendToSlackLiner(ctx context.Context, payload SlackLinerPayloa
 	}
 
 	if err := s.redisClient.RPush(ctx, s.config.SlackLinerListName, data); err != nil {
+		s.metrics.rpushFailed(s.config.SlackLinerListName)
 		return fmt.Errorf("failed to push to Redis list: %w", err)
 	}
+	s.metrics.slackLinerPosted()
 
 	return nil
 }
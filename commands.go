@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Slash command subcommands that are not project names
+	SubcommandList = "list"
+	SubcommandHelp = "help"
+
+	// Options, given as --option value or --option=value
+	FlagTail = "--tail" // Overrides the number of log lines
+	FlagRef  = "--ref"  // Runs the command against another git branch or ref
+)
+
+// commandKind is what a /slack-compose invocation asks for
+type commandKind int
+
+const (
+	commandDialog commandKind = iota // No arguments: show the Block Kit dialog
+	commandList                      // List the projects that can be controlled from the channel
+	commandHelp                      // Show usage
+	commandRun                       // Run an action on a project
+)
+
+// parsedCommand is the parsed text of a /slack-compose invocation
+type parsedCommand struct {
+	Kind     commandKind
+	Project  string
+	Action   string   // Action name; empty runs the default action
+	Services []string // Services to target; empty targets the whole stack
+	Tail     int      // Log lines requested with --tail; 0 uses the configured limit
+	Ref      string   // Git ref requested with --ref; empty uses the project's branch
+}
+
+// usageError is a mistake in the command text, explained to the user with the usage
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// parseCommandText parses the /slack-compose grammar:
+//
+//	/slack-compose
+//	/slack-compose list
+//	/slack-compose help
+//	/slack-compose <project> [action [service...]] [--tail N] [--ref REF]
+func parseCommandText(text string) (parsedCommand, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return parsedCommand{Kind: commandDialog}, nil
+	}
+
+	switch fields[0] {
+	case SubcommandList, SubcommandHelp:
+		if len(fields) > 1 {
+			return parsedCommand{}, &usageError{fmt.Sprintf("`%s` takes no arguments", fields[0])}
+		}
+		if fields[0] == SubcommandList {
+			return parsedCommand{Kind: commandList}, nil
+		}
+		return parsedCommand{Kind: commandHelp}, nil
+	}
+
+	cmd := parsedCommand{Kind: commandRun, Project: fields[0]}
+	for i := 1; i < len(fields); i++ {
+		field := fields[i]
+
+		if strings.HasPrefix(field, "-") {
+			name, value, hasValue := strings.Cut(field, "=")
+			if name != FlagTail && name != FlagRef {
+				return parsedCommand{}, &usageError{fmt.Sprintf("unknown option `%s`", name)}
+			}
+			if !hasValue {
+				if i+1 >= len(fields) {
+					return parsedCommand{}, &usageError{fmt.Sprintf("`%s` needs a value", name)}
+				}
+				i++
+				value = fields[i]
+			}
+
+			switch name {
+			case FlagTail:
+				lines, err := strconv.Atoi(value)
+				if err != nil || lines <= 0 {
+					return parsedCommand{}, &usageError{fmt.Sprintf("`--tail` needs a positive number of lines, got `%s`", value)}
+				}
+				cmd.Tail = lines
+			case FlagRef:
+				if !validGitRef(value) {
+					return parsedCommand{}, &usageError{fmt.Sprintf("`--ref` needs a branch or ref name, got `%s`", value)}
+				}
+				cmd.Ref = value
+			}
+			continue
+		}
+
+		if cmd.Action == "" {
+			cmd.Action = field
+		} else {
+			cmd.Services = append(cmd.Services, field)
+		}
+	}
+
+	if cmd.Tail > 0 && cmd.Action == "" {
+		return parsedCommand{}, &usageError{"`--tail` needs an action, e.g. `logs`"}
+	}
+
+	return cmd, nil
+}
+
+// runCommand builds the command request for a parsed invocation and submits it
+func (s *Service) runCommand(ctx context.Context, cmd SlackCommand, parsed parsedCommand) {
+	// Check if project exists in config
+	project, exists := s.config.Project(parsed.Project)
+	if !exists {
+		slog.Warn("Unknown project requested, showing block kit dialog", "project", parsed.Project)
+		s.metrics.eventIgnored(IgnoreReasonUnknownProject)
+		s.sendBlockKitDialog(ctx, cmd.ChannelID)
+		return
+	}
+
+	// Refuse to control the project from channels it is not allowed in
+	if !project.AllowsChannel(cmd.ChannelID) {
+		slog.Warn("Project not allowed in channel", "project", parsed.Project, "channel", cmd.ChannelID)
+		s.sendNotice(ctx, cmd.ChannelID, "", channelNotAllowedMessage(project))
+		return
+	}
+
+	// Without an action the default (ps) command is run
+	actionName := parsed.Action
+	if actionName == "" {
+		actionName = DefaultCommandAction
+	}
+	action, ok := s.config.ActionByName(actionName)
+	if !ok {
+		slog.Warn("Unknown action requested", "action", actionName, "project", parsed.Project)
+		s.sendUsage(ctx, cmd.ChannelID, fmt.Sprintf("unknown action `%s`", actionName))
+		return
+	}
+
+	// Only projects that allow it may run commands against another ref
+	if parsed.Ref != "" && !project.AllowRefOverride {
+		s.sendUsage(ctx, cmd.ChannelID, fmt.Sprintf("project `%s` does not allow `--ref`", project.Name))
+		return
+	}
+
+	// --tail only makes sense for actions showing logs
+	lines := s.config.DockerLogsLineLimit
+	if parsed.Tail > 0 {
+		if !strings.Contains(action.Command, CommandPlaceholderLogLines) {
+			s.sendUsage(ctx, cmd.ChannelID, fmt.Sprintf("`--tail` does not apply to `%s`", action.Name))
+			return
+		}
+		lines = parsed.Tail
+	}
+
+	req := commandRequest{
+		Project:       project,
+		Action:        action,
+		Command:       withServices(expandCommandTemplate(action.Command, lines), parsed.Services),
+		Services:      parsed.Services,
+		UserID:        cmd.UserID,
+		Source:        SourceCommand,
+		SourceChannel: cmd.ChannelID,
+		Ref:           parsed.Ref,
+	}
+
+	s.submit(ctx, req)
+}
+
+// sendProjectList replies with the projects that can be controlled from the channel
+func (s *Service) sendProjectList(ctx context.Context, channel string) {
+	projects := s.config.projects()
+
+	var names []string
+	for name, project := range projects {
+		if project.AllowsChannel(channel) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		s.sendNotice(ctx, channel, "", "No projects can be controlled from this channel.")
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("*Projects:*")
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n• `%s`", inlineCode(name))
+		if services := projects[name].Services; len(services) > 0 {
+			fmt.Fprintf(&b, " (services: %s)", escapeMrkdwn(strings.Join(services, ", ")))
+		}
+	}
+	s.sendNotice(ctx, channel, "", b.String())
+}
+
+// sendUsage replies with a usage error followed by the command usage
+func (s *Service) sendUsage(ctx context.Context, channel, problem string) {
+	// The problem may quote the user's text, which must not be read as mentions or links
+	problem = escapeMrkdwn(problem)
+	s.sendNotice(ctx, channel, "", fmt.Sprintf(":warning: %s%s.\n\n%s", strings.ToUpper(problem[:1]), problem[1:], s.usage()))
+}
+
+// usage describes the /slack-compose grammar and the available actions
+func (s *Service) usage() string {
+	return fmt.Sprintf("*Usage:*\n"+
+		"• `/slack-compose` - open the project dialog\n"+
+		"• `/slack-compose list` - list the projects you can control here\n"+
+		"• `/slack-compose help` - show this help\n"+
+		"• `/slack-compose <project>` - run `%s` on the project\n"+
+		"• `/slack-compose <project> <action> [service...] [--tail N] [--ref REF]` - run an action, optionally on some services or another git ref\n"+
+		"*Actions:* %s", DefaultCommandAction, s.actionNames())
+}
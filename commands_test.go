@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCommandText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want parsedCommand
+	}{
+		{"empty shows dialog", "  ", parsedCommand{Kind: commandDialog}},
+		{"list", "list", parsedCommand{Kind: commandList}},
+		{"help", "help", parsedCommand{Kind: commandHelp}},
+		{"project only", "my-project", parsedCommand{Kind: commandRun, Project: "my-project"}},
+		{"action", "my-project up", parsedCommand{Kind: commandRun, Project: "my-project", Action: "up"}},
+		{"action and services", "my-project restart web worker", parsedCommand{Kind: commandRun, Project: "my-project", Action: "restart", Services: []string{"web", "worker"}}},
+		{"tail", "my-project logs web --tail 20", parsedCommand{Kind: commandRun, Project: "my-project", Action: "logs", Services: []string{"web"}, Tail: 20}},
+		{"tail with equals", "my-project logs --tail=5", parsedCommand{Kind: commandRun, Project: "my-project", Action: "logs", Tail: 5}},
+		{"ref", "my-project up --ref=release/1.2", parsedCommand{Kind: commandRun, Project: "my-project", Action: "up", Ref: "release/1.2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCommandText(tt.text)
+			if err != nil {
+				t.Fatalf("parseCommandText(%q) error = %v", tt.text, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCommandText(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCommandText_UsageErrors(t *testing.T) {
+	tests := []string{
+		"list everything",
+		"help me",
+		"my-project logs --tail",
+		"my-project logs --tail abc",
+		"my-project logs --tail 0",
+		"my-project logs --follow",
+		"my-project --tail 10",
+		"my-project up --ref",
+		"my-project up --ref ../main",
+	}
+
+	for _, text := range tests {
+		if _, err := parseCommandText(text); err == nil {
+			t.Errorf("parseCommandText(%q) expected a usage error", text)
+		}
+	}
+}
+
+func sendCommand(svc *Service, text string) {
+	data, _ := json.Marshal(SlackCommand{Command: "/slack-compose", Text: text, ChannelID: "C123", UserID: "U1"})
+	svc.handleCommand(context.Background(), string(data))
+}
+
+func TestHandleCommand_Tail(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	sendCommand(svc, "my-project logs web --tail 20")
+
+	if len(rc.pushed) != 1 || rc.pushed[0].key != "poppit:notifications" {
+		t.Fatalf("expected 1 push to Poppit, got %+v", rc.pushed)
+	}
+	var pp PoppitPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &pp)
+	if pp.Commands[0] != "docker compose logs -n 20 web" {
+		t.Errorf("command = %q, want %q", pp.Commands[0], "docker compose logs -n 20 web")
+	}
+}
+
+func TestHandleCommand_UsageErrors_SendNotice(t *testing.T) {
+	for _, text := range []string{"my-project logs --tail x", "my-project restart --tail 5", "my-project explode"} {
+		rc := &mockRedisClient{}
+		svc := newTestService(rc, nil)
+
+		sendCommand(svc, text)
+
+		if len(rc.pushed) != 1 || rc.pushed[0].key != "slack_messages" {
+			t.Fatalf("%q: expected 1 notice and nothing sent to Poppit, got %+v", text, rc.pushed)
+		}
+		var slp SlackLinerPayload
+		json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+		if !strings.Contains(slp.Text, "*Usage:*") {
+			t.Errorf("%q: notice should include the usage, got %q", text, slp.Text)
+		}
+	}
+}
+
+func TestHandleCommand_List(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	sendCommand(svc, "list")
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(rc.pushed))
+	}
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+	if !strings.Contains(slp.Text, "my-project") || !strings.Contains(slp.Text, "web, worker") {
+		t.Errorf("list should include my-project and its services, got %q", slp.Text)
+	}
+	// prod-project is restricted to another channel
+	if strings.Contains(slp.Text, "prod-project") {
+		t.Errorf("list should not include projects not allowed in the channel, got %q", slp.Text)
+	}
+}
+
+func TestHandleCommand_Help(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	sendCommand(svc, "help")
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(rc.pushed))
+	}
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+	if !strings.Contains(slp.Text, "*Usage:*") || !strings.Contains(slp.Text, "`restart`") {
+		t.Errorf("help should describe the usage and actions, got %q", slp.Text)
+	}
+}
+
+func TestHandleCommand_DestructiveRequestsConfirmation(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	sendCommand(svc, "my-project down")
+
+	if len(rc.pushed) != 1 || rc.pushed[0].key != "slack_messages" {
+		t.Fatalf("expected 1 confirmation prompt and nothing sent to Poppit, got %+v", rc.pushed)
+	}
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+	if slp.Metadata.EventType != EventTypeConfirmation {
+		t.Errorf("event type = %q, want %q", slp.Metadata.EventType, EventTypeConfirmation)
+	}
+	if slp.Channel != "C123" {
+		t.Errorf("channel = %q, want %q", slp.Channel, "C123")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// composePrefix starts the commands that project compose options are applied to
+const composePrefix = "docker compose"
+
+var (
+	// composeArgPattern restricts file names and profiles to characters that need no quoting
+	composeArgPattern = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+
+	// composeProjectNamePattern is docker compose's rule for project names
+	composeProjectNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+)
+
+// composeOptions returns the docker compose global options configured for the project
+func (p ProjectConfig) composeOptions() []string {
+	var options []string
+	for _, file := range p.ComposeFiles {
+		options = append(options, "-f", file)
+	}
+	for _, profile := range p.Profiles {
+		options = append(options, "--profile", profile)
+	}
+	for _, envFile := range p.EnvFiles {
+		options = append(options, "--env-file", envFile)
+	}
+	if p.ProjectName != "" {
+		options = append(options, "-p", p.ProjectName)
+	}
+	return options
+}
+
+// applyComposeOptions inserts the project's compose options after "docker compose".
+// Commands that are not docker compose commands are returned unchanged.
+func (p ProjectConfig) applyComposeOptions(command string) string {
+	options := p.composeOptions()
+	rest, ok := strings.CutPrefix(command, composePrefix+" ")
+	if len(options) == 0 || !ok {
+		return command
+	}
+	return composePrefix + " " + strings.Join(options, " ") + " " + rest
+}
+
+// validateComposeOptions reports problems with the project's compose options
+func (p ProjectConfig) validateComposeOptions(label string) []string {
+	var problems []string
+	for _, option := range []struct {
+		key    string
+		values []string
+	}{
+		{"compose_files", p.ComposeFiles},
+		{"profiles", p.Profiles},
+		{"env_files", p.EnvFiles},
+	} {
+		for _, value := range option.values {
+			if !composeArgPattern.MatchString(value) {
+				problems = append(problems, fmt.Sprintf("%s: invalid %s entry %q (letters, digits, '.', '_', '-' and '/' only)", label, option.key, value))
+			}
+		}
+	}
+	if p.ProjectName != "" && !composeProjectNamePattern.MatchString(p.ProjectName) {
+		problems = append(problems, fmt.Sprintf("%s: invalid project_name %q (lowercase letters, digits, '-' and '_', starting with a letter or digit)", label, p.ProjectName))
+	}
+	return problems
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyComposeOptions(t *testing.T) {
+	project := ProjectConfig{
+		ComposeFiles: []string{"docker-compose.yml", "docker-compose.prod.yml"},
+		Profiles:     []string{"monitoring"},
+		EnvFiles:     []string{".env.prod"},
+		ProjectName:  "web",
+	}
+
+	tests := []struct {
+		project ProjectConfig
+		command string
+		want    string
+	}{
+		{project, "docker compose up -d", "docker compose -f docker-compose.yml -f docker-compose.prod.yml --profile monitoring --env-file .env.prod -p web up -d"},
+		{project, "docker compose logs -n 100 web", "docker compose -f docker-compose.yml -f docker-compose.prod.yml --profile monitoring --env-file .env.prod -p web logs -n 100 web"},
+		{project, "./deploy.sh", "./deploy.sh"},
+		{ProjectConfig{}, "docker compose ps", "docker compose ps"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.project.applyComposeOptions(tt.command); got != tt.want {
+			t.Errorf("applyComposeOptions(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestValidateComposeOptions(t *testing.T) {
+	valid := ProjectConfig{ComposeFiles: []string{"compose/prod.yml"}, Profiles: []string{"monitoring"}, EnvFiles: []string{".env"}, ProjectName: "web_1"}
+	if problems := valid.validateComposeOptions("p"); len(problems) != 0 {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+
+	invalid := ProjectConfig{ComposeFiles: []string{"a b.yml"}, Profiles: []string{"x;rm"}, EnvFiles: []string{""}, ProjectName: "Web"}
+	if problems := invalid.validateComposeOptions("p"); len(problems) != 4 {
+		t.Errorf("expected 4 problems, got %v", problems)
+	}
+}
+
+func TestComposeOptions_AppliedFromEveryEntryPoint(t *testing.T) {
+	const want = "docker compose -f docker-compose.prod.yml -p prod restart"
+
+	newService := func(rc *mockRedisClient, sc SlackClientInterface) *Service {
+		svc := newTestService(rc, sc)
+		svc.config.Projects["my-project"] = ProjectConfig{
+			Name:         "my-project",
+			WorkingDir:   "/srv/my-project",
+			ComposeFiles: []string{"docker-compose.prod.yml"},
+			ProjectName:  "prod",
+		}
+		return svc
+	}
+	poppitCommand := func(t *testing.T, rc *mockRedisClient) string {
+		t.Helper()
+		if len(rc.pushed) != 1 || rc.pushed[0].key != "poppit:notifications" {
+			t.Fatalf("expected 1 push to Poppit, got %+v", rc.pushed)
+		}
+		var pp PoppitPayload
+		json.Unmarshal(rc.pushed[0].value.([]byte), &pp)
+		return pp.Commands[0]
+	}
+
+	t.Run("command", func(t *testing.T) {
+		rc := &mockRedisClient{}
+		sendCommand(newService(rc, nil), "my-project restart")
+		if got := poppitCommand(t, rc); got != want {
+			t.Errorf("command = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("reaction", func(t *testing.T) {
+		rc := &mockRedisClient{}
+		sc := &mockSlackClient{message: &SlackMessage{Metadata: SlackMetadata{
+			EventType:    "slack-compose",
+			EventPayload: map[string]interface{}{"project": "my-project"},
+		}}}
+		data, _ := json.Marshal(SlackReaction{Event: SlackReactionEvent{
+			Reaction: EmojiArrowsCounterClockwise,
+			Item:     SlackReactionItem{Channel: "C123", TS: "111.222"},
+		}})
+		newService(rc, sc).handleReaction(context.Background(), string(data))
+		if got := poppitCommand(t, rc); got != want {
+			t.Errorf("command = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("button", func(t *testing.T) {
+		rc := &mockRedisClient{}
+		data, _ := json.Marshal(SlackBlockAction{
+			Type:    "block_actions",
+			Actions: []BlockActionElement{{ActionID: ActionDockerRestart, Type: "button"}},
+			State: BlockActionState{Values: map[string]map[string]BlockActionValue{
+				BlockIDProjectBlock: {ActionIDSlackCompose: {SelectedOption: &BlockActionOption{Value: "my-project"}}},
+			}},
+			Channel: BlockActionChannel{ID: "C123"},
+		})
+		newService(rc, nil).handleBlockAction(context.Background(), string(data))
+		if got := poppitCommand(t, rc); got != want {
+			t.Errorf("command = %q, want %q", got, want)
+		}
+	})
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 // Config holds all configuration for the service
@@ -13,10 +17,18 @@ type Config struct {
 	RedisPassword string
 	RedisDB       int
 
+	// Redis transport for incoming events: pubsub or streams
+	RedisTransport           string
+	RedisConsumerGroup       string // Consumer group used by the streams transport
+	RedisConsumerName        string // Consumer name within the group; should be stable across restarts
+	RedisClaimMinIdleSeconds int    // Idle time after which other consumers' pending entries are reclaimed
+	RedisReconnectMaxSeconds int    // Longest wait between attempts to restore a lost subscription
+
 	// Service configuration
 	SlackCommandChannel      string // Redis channel to listen for Slack commands
 	SlackReactionChannel     string // Redis channel to listen for Slack reactions
 	SlackBlockActionsChannel string // Redis channel to listen for Slack block actions
+	SlackSuggestionsChannel  string // Redis channel to listen for Slack block suggestions (external select queries)
 	PoppitListName           string // Redis list name for Poppit notifications
 	PoppitOutputChannel      string // Redis channel to listen for Poppit command output
 	SlackLinerListName       string // Redis list name for SlackLiner messages
@@ -29,32 +41,216 @@ type Config struct {
 	// Docker compose logs line limit
 	DockerLogsLineLimit int
 
-	// Project mappings (loaded from config file)
-	Projects map[string]ProjectConfig
+	// Splitting of long command output over several messages
+	OutputChunkSize int // Maximum size of each message in bytes
+	OutputMaxChunks int // Maximum number of messages per command output
+
+	// Upload of large command output as a file snippet
+	OutputUploadThreshold int // Output size in bytes above which it is uploaded; 0 disables uploads
+	OutputPreviewLines    int // Lines from the start and end of uploaded output posted inline
+
+	// How long a destructive action waits for confirmation
+	ConfirmationTimeoutSeconds int
+
+	// Action catalog (loaded from config file)
+	ActionsConfigPath string
+	Actions           []Action
+
+	// Audit log sinks; each is disabled when empty
+	AuditLogPath    string // Path of the append-only JSONL audit file
+	AuditStreamName string // Redis stream receiving audit records
+
+	// Access control
+	UserRoles   map[string]Role // Global role assignments keyed by Slack user ID
+	DefaultRole Role            // Role for users without an assignment
+
+	// Hot reload of the project config file
+	ProjectConfigWatchSeconds int  // Interval between checks of the file for changes; 0 disables watching
+	ProjectReloadNotify       bool // Post a summary of each reload to the Slack channel
+
+	// Address of the HTTP listener serving /healthz, /readyz, /status and /metrics; empty disables it
+	HTTPAddr string
+
+	// Seconds in-flight handlers get to finish when the service stops
+	ShutdownTimeoutSeconds int
+
+	// Worker pool handling events
+	WorkerPoolSize        int    // Number of workers handling events concurrently
+	WorkerQueueSize       int    // Events each worker can have waiting
+	WorkerQueueFullPolicy string // What happens to an event whose worker's queue is full: block or drop
+
+	// Project mappings (loaded from config file). The map is replaced, never modified, on reload;
+	// once the service is running read it through Project and projects.
+	Projects   map[string]ProjectConfig
+	projectsMu sync.RWMutex
 }
 
 // ProjectConfig maps a project name to its working directory
 type ProjectConfig struct {
-	Name       string `json:"name"`
-	WorkingDir string `json:"working_dir"`
+	Name       string `json:"name" yaml:"name" toml:"name"`
+	WorkingDir string `json:"working_dir" yaml:"working_dir" toml:"working_dir"`
+
+	// AllowedChannels lists the Slack channel IDs the project may be controlled from.
+	// An empty list allows every channel.
+	AllowedChannels []string `json:"allowed_channels,omitempty" yaml:"allowed_channels,omitempty" toml:"allowed_channels,omitempty"`
+
+	// Roles assigns project-specific roles keyed by Slack user ID, overriding global assignments
+	Roles map[string]Role `json:"roles,omitempty" yaml:"roles,omitempty" toml:"roles,omitempty"`
+
+	// Services lists the project's docker compose services that commands may target individually
+	Services []string `json:"services,omitempty" yaml:"services,omitempty" toml:"services,omitempty"`
+
+	// Docker compose options added to every command run for the project: compose files (-f) and
+	// env files (--env-file), both relative to working_dir, profiles (--profile) and the project name (-p)
+	ComposeFiles []string `json:"compose_files,omitempty" yaml:"compose_files,omitempty" toml:"compose_files,omitempty"`
+	EnvFiles     []string `json:"env_files,omitempty" yaml:"env_files,omitempty" toml:"env_files,omitempty"`
+	Profiles     []string `json:"profiles,omitempty" yaml:"profiles,omitempty" toml:"profiles,omitempty"`
+	ProjectName  string   `json:"project_name,omitempty" yaml:"project_name,omitempty" toml:"project_name,omitempty"`
+
+	// Branch is the git branch (or full ref) Poppit checks out for the project; defaults to main
+	Branch string `json:"branch,omitempty" yaml:"branch,omitempty" toml:"branch,omitempty"`
+
+	// Redact lists regular expressions for project-specific secrets masked in command output, on top of
+	// the built-in detectors. A pattern with a capture group masks only the first group.
+	Redact []string `json:"redact,omitempty" yaml:"redact,omitempty" toml:"redact,omitempty"`
+
+	// AllowRefOverride lets /slack-compose --ref run a command against another branch or ref
+	AllowRefOverride bool `json:"allow_ref_override,omitempty" yaml:"allow_ref_override,omitempty" toml:"allow_ref_override,omitempty"`
+}
+
+// AllowsChannel reports whether the project may be controlled from the given Slack channel
+func (p ProjectConfig) AllowsChannel(channel string) bool {
+	if len(p.AllowedChannels) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedChannels {
+		if allowed == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateServices checks that every name is one of the project's configured services
+func (p ProjectConfig) ValidateServices(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if len(p.Services) == 0 {
+		return fmt.Errorf("project %s has no services configured for targeting", p.Name)
+	}
+
+	known := make(map[string]bool, len(p.Services))
+	for _, service := range p.Services {
+		known[service] = true
+	}
+
+	var unknown []string
+	for _, name := range names {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown service(s) %s for project %s (available: %s)",
+			strings.Join(unknown, ", "), p.Name, strings.Join(p.Services, ", "))
+	}
+	return nil
 }
 
 // LoadConfig loads configuration from environment variables
 func LoadConfig() (*Config, error) {
 	config := &Config{
-		RedisAddr:                getEnv("REDIS_ADDR", "localhost:6379"),
-		RedisPassword:            getEnv("REDIS_PASSWORD", ""),
-		RedisDB:                  getEnvInt("REDIS_DB", 0),
-		SlackCommandChannel:      getEnv("SLACK_COMMAND_CHANNEL", "slack-commands"),
-		SlackReactionChannel:     getEnv("SLACK_REACTION_CHANNEL", "slack-reactions"),
-		SlackBlockActionsChannel: getEnv("SLACK_BLOCK_ACTIONS_CHANNEL", "slack-relay-block-actions"),
-		PoppitListName:           getEnv("POPPIT_LIST_NAME", "poppit:notifications"),
-		PoppitOutputChannel:      getEnv("POPPIT_OUTPUT_CHANNEL", "poppit:command-output"),
-		SlackLinerListName:       getEnv("SLACKLINER_LIST_NAME", "slack_messages"),
-		SlackToken:               getEnv("SLACK_BOT_TOKEN", ""),
-		SlackChannel:             getEnv("SLACK_CHANNEL", "#slack-compose"),
-		ProjectConfigPath:        getEnv("PROJECT_CONFIG_PATH", "projects.json"),
-		DockerLogsLineLimit:      getEnvInt("DOCKER_LOGS_LINE_LIMIT", 100),
+		RedisAddr:                  getEnv("REDIS_ADDR", "localhost:6379"),
+		RedisPassword:              getEnv("REDIS_PASSWORD", ""),
+		RedisDB:                    getEnvInt("REDIS_DB", 0),
+		RedisTransport:             getEnv("REDIS_TRANSPORT", TransportPubSub),
+		RedisConsumerGroup:         getEnv("REDIS_CONSUMER_GROUP", "slackcompose"),
+		RedisConsumerName:          getEnv("REDIS_CONSUMER_NAME", defaultConsumerName()),
+		RedisClaimMinIdleSeconds:   getEnvInt("REDIS_CLAIM_MIN_IDLE_SECONDS", 60),
+		RedisReconnectMaxSeconds:   getEnvInt("REDIS_RECONNECT_MAX_SECONDS", DefaultRedisReconnectMaxSeconds),
+		SlackCommandChannel:        getEnv("SLACK_COMMAND_CHANNEL", "slack-commands"),
+		SlackReactionChannel:       getEnv("SLACK_REACTION_CHANNEL", "slack-reactions"),
+		SlackBlockActionsChannel:   getEnv("SLACK_BLOCK_ACTIONS_CHANNEL", "slack-relay-block-actions"),
+		SlackSuggestionsChannel:    getEnv("SLACK_SUGGESTIONS_CHANNEL", "slack-relay-block-suggestions"),
+		PoppitListName:             getEnv("POPPIT_LIST_NAME", "poppit:notifications"),
+		PoppitOutputChannel:        getEnv("POPPIT_OUTPUT_CHANNEL", "poppit:command-output"),
+		SlackLinerListName:         getEnv("SLACKLINER_LIST_NAME", "slack_messages"),
+		SlackToken:                 getEnv("SLACK_BOT_TOKEN", ""),
+		SlackChannel:               getEnv("SLACK_CHANNEL", "#slack-compose"),
+		ProjectConfigPath:          getEnv("PROJECT_CONFIG_PATH", "projects.json"),
+		DockerLogsLineLimit:        getEnvInt("DOCKER_LOGS_LINE_LIMIT", 100),
+		OutputChunkSize:            getEnvInt("OUTPUT_CHUNK_SIZE", DefaultOutputChunkSize),
+		OutputMaxChunks:            getEnvInt("OUTPUT_MAX_CHUNKS", DefaultOutputMaxChunks),
+		OutputUploadThreshold:      getEnvInt("OUTPUT_UPLOAD_THRESHOLD", DefaultOutputUploadThreshold),
+		OutputPreviewLines:         getEnvInt("OUTPUT_PREVIEW_LINES", DefaultOutputPreviewLines),
+		ActionsConfigPath:          getEnv("ACTIONS_CONFIG_PATH", "actions.json"),
+		ConfirmationTimeoutSeconds: getEnvInt("CONFIRMATION_TIMEOUT_SECONDS", DefaultConfirmationTimeoutSeconds),
+		AuditLogPath:               getEnv("AUDIT_LOG_PATH", ""),
+		AuditStreamName:            getEnv("AUDIT_STREAM_NAME", ""),
+		ProjectConfigWatchSeconds:  getEnvInt("PROJECT_CONFIG_WATCH_SECONDS", 5),
+		ProjectReloadNotify:        getEnvBool("PROJECT_RELOAD_NOTIFY", false),
+		HTTPAddr:                   getEnv("HTTP_ADDR", ""),
+		ShutdownTimeoutSeconds:     getEnvInt("SHUTDOWN_TIMEOUT_SECONDS", DefaultShutdownTimeoutSeconds),
+		WorkerPoolSize:             getEnvInt("WORKER_POOL_SIZE", DefaultWorkerPoolSize),
+		WorkerQueueSize:            getEnvInt("WORKER_QUEUE_SIZE", DefaultWorkerQueueSize),
+		WorkerQueueFullPolicy:      getEnv("WORKER_QUEUE_FULL_POLICY", QueueFullBlock),
+	}
+
+	if config.RedisTransport != TransportPubSub && config.RedisTransport != TransportStreams {
+		return nil, fmt.Errorf("invalid REDIS_TRANSPORT %q (expected %s or %s)", config.RedisTransport, TransportPubSub, TransportStreams)
+	}
+
+	if config.RedisReconnectMaxSeconds < 1 {
+		return nil, fmt.Errorf("invalid REDIS_RECONNECT_MAX_SECONDS %d (minimum 1)", config.RedisReconnectMaxSeconds)
+	}
+
+	if config.ShutdownTimeoutSeconds < 1 {
+		return nil, fmt.Errorf("invalid SHUTDOWN_TIMEOUT_SECONDS %d (minimum 1)", config.ShutdownTimeoutSeconds)
+	}
+
+	if config.WorkerPoolSize < 1 {
+		return nil, fmt.Errorf("invalid WORKER_POOL_SIZE %d (minimum 1)", config.WorkerPoolSize)
+	}
+	if config.WorkerQueueSize < 1 {
+		return nil, fmt.Errorf("invalid WORKER_QUEUE_SIZE %d (minimum 1)", config.WorkerQueueSize)
+	}
+	if config.WorkerQueueFullPolicy != QueueFullBlock && config.WorkerQueueFullPolicy != QueueFullDrop {
+		return nil, fmt.Errorf("invalid WORKER_QUEUE_FULL_POLICY %q (expected %s or %s)", config.WorkerQueueFullPolicy, QueueFullBlock, QueueFullDrop)
+	}
+
+	if config.OutputChunkSize < MinOutputChunkSize {
+		return nil, fmt.Errorf("invalid OUTPUT_CHUNK_SIZE %d (minimum %d)", config.OutputChunkSize, MinOutputChunkSize)
+	}
+	if config.OutputMaxChunks < 1 {
+		return nil, fmt.Errorf("invalid OUTPUT_MAX_CHUNKS %d (minimum 1)", config.OutputMaxChunks)
+	}
+
+	if config.OutputPreviewLines < 1 {
+		return nil, fmt.Errorf("invalid OUTPUT_PREVIEW_LINES %d (minimum 1)", config.OutputPreviewLines)
+	}
+
+	// Load access control configuration
+	userRoles, err := parseUserRoles(getEnv("USER_ROLES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid USER_ROLES: %w", err)
+	}
+	config.UserRoles = userRoles
+
+	// Once roles are assigned, unassigned users default to viewer unless configured otherwise
+	if defaultRole := getEnv("DEFAULT_ROLE", ""); defaultRole != "" {
+		role, err := parseRole(defaultRole)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEFAULT_ROLE: %w", err)
+		}
+		config.DefaultRole = role
+	} else if len(userRoles) > 0 {
+		config.DefaultRole = RoleViewer
+	}
+
+	// Load action catalog
+	if err := config.loadActionConfig(); err != nil {
+		return nil, fmt.Errorf("failed to load action config: %w", err)
 	}
 
 	// Load project configuration
@@ -74,28 +270,151 @@ func LoadConfig() (*Config, error) {
 func (c *Config) loadProjectConfig() error {
 	// If file doesn't exist, initialize with empty map
 	if _, err := os.Stat(c.ProjectConfigPath); os.IsNotExist(err) {
-		c.Projects = make(map[string]ProjectConfig)
+		c.setProjects(make(map[string]ProjectConfig))
 		return nil
 	}
 
-	data, err := os.ReadFile(c.ProjectConfigPath)
+	projects, err := readProjectConfig(c.ProjectConfigPath)
+	if err != nil {
+		return err
+	}
+
+	c.setProjects(projects)
+	return nil
+}
+
+// readProjectConfig reads and validates a project config file
+func readProjectConfig(path string) (map[string]ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config file: %w", err)
+	}
+
+	projects, err := parseProjectConfig(path, data)
 	if err != nil {
-		return fmt.Errorf("failed to read project config file: %w", err)
+		return nil, err
 	}
 
-	var projects []ProjectConfig
-	if err := json.Unmarshal(data, &projects); err != nil {
-		return fmt.Errorf("failed to parse project config: %w", err)
+	if err := validateProjects(projects, false); err != nil {
+		return nil, err
 	}
 
-	c.Projects = make(map[string]ProjectConfig)
+	result := make(map[string]ProjectConfig, len(projects))
 	for _, p := range projects {
-		c.Projects[p.Name] = p
+		result[p.Name] = p
+	}
+	return result, nil
+}
+
+// ValidationErrors aggregates every problem found in a configuration file
+type ValidationErrors []string
+
+func (v ValidationErrors) Error() string {
+	return fmt.Sprintf("%d configuration problem(s):\n  - %s", len(v), strings.Join(v, "\n  - "))
+}
+
+// validateProjects checks every project and reports all problems at once.
+// checkDirs also requires each working directory to exist on this host, which is only
+// meaningful where the commands run (Poppit's host), so it is off at startup.
+func validateProjects(projects []ProjectConfig, checkDirs bool) error {
+	var problems ValidationErrors
+	seen := make(map[string]int)
+
+	for i, p := range projects {
+		label := fmt.Sprintf("project %d (%q)", i+1, p.Name)
+
+		switch {
+		case p.Name == "":
+			problems = append(problems, fmt.Sprintf("project %d: name is required", i+1))
+		case strings.ContainsAny(p.Name, " \t\n"):
+			problems = append(problems, fmt.Sprintf("%s: name must not contain whitespace", label))
+		case p.Name == SubcommandList || p.Name == SubcommandHelp:
+			problems = append(problems, fmt.Sprintf("%s: name is reserved for the /slack-compose %s subcommand", label, p.Name))
+		}
+		if first, duplicate := seen[p.Name]; duplicate && p.Name != "" {
+			problems = append(problems, fmt.Sprintf("%s: duplicate of project %d", label, first))
+		} else {
+			seen[p.Name] = i + 1
+		}
+
+		switch {
+		case p.WorkingDir == "":
+			problems = append(problems, fmt.Sprintf("%s: working_dir is required", label))
+		case !filepath.IsAbs(p.WorkingDir):
+			problems = append(problems, fmt.Sprintf("%s: working_dir %q must be an absolute path", label, p.WorkingDir))
+		case checkDirs:
+			if info, err := os.Stat(p.WorkingDir); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: working_dir %q does not exist", label, p.WorkingDir))
+			} else if !info.IsDir() {
+				problems = append(problems, fmt.Sprintf("%s: working_dir %q is not a directory", label, p.WorkingDir))
+			}
+		}
+
+		for _, userID := range sortedKeys(p.Roles) {
+			if role := p.Roles[userID]; !role.Valid() {
+				problems = append(problems, fmt.Sprintf("%s: unknown role %q for user %s", label, role, userID))
+			}
+		}
+		for _, service := range p.Services {
+			if service == "" || strings.ContainsAny(service, " \t\n") {
+				problems = append(problems, fmt.Sprintf("%s: invalid service name %q", label, service))
+			}
+		}
+		problems = append(problems, p.validateComposeOptions(label)...)
+		if p.Branch != "" && !validGitRef(p.Branch) {
+			problems = append(problems, fmt.Sprintf("%s: invalid branch %q", label, p.Branch))
+		}
+		for _, pattern := range p.Redact {
+			if _, err := regexp.Compile(pattern); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: invalid redact pattern %q: %v", label, pattern, err))
+			}
+		}
+		for _, channel := range p.AllowedChannels {
+			if strings.TrimSpace(channel) == "" {
+				problems = append(problems, fmt.Sprintf("%s: allowed_channels contains an empty channel ID", label))
+			}
+		}
 	}
 
+	if len(problems) > 0 {
+		return problems
+	}
 	return nil
 }
 
+// Project returns the configuration of a project
+func (c *Config) Project(name string) (ProjectConfig, bool) {
+	c.projectsMu.RLock()
+	defer c.projectsMu.RUnlock()
+
+	project, ok := c.Projects[name]
+	return project, ok
+}
+
+// projects returns the current project map, which must not be modified
+func (c *Config) projects() map[string]ProjectConfig {
+	c.projectsMu.RLock()
+	defer c.projectsMu.RUnlock()
+
+	return c.Projects
+}
+
+// setProjects atomically replaces the project map
+func (c *Config) setProjects(projects map[string]ProjectConfig) {
+	c.projectsMu.Lock()
+	defer c.projectsMu.Unlock()
+
+	c.Projects = projects
+}
+
+// defaultConsumerName returns the host name, used as the stream consumer name when none is configured
+func defaultConsumerName() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return "slackcompose"
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -103,6 +422,15 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		var intValue int
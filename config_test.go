@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -50,6 +51,33 @@ func TestGetEnvInt(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_RedisTransport(t *testing.T) {
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("PROJECT_CONFIG_PATH", filepath.Join(t.TempDir(), "projects.json"))
+	t.Setenv("ACTIONS_CONFIG_PATH", filepath.Join(t.TempDir(), "actions.json"))
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.RedisTransport != TransportPubSub || config.RedisConsumerGroup != "slackcompose" ||
+		config.RedisConsumerName == "" || config.RedisClaimMinIdleSeconds != 60 {
+		t.Errorf("defaults = %q %q %q %d", config.RedisTransport, config.RedisConsumerGroup,
+			config.RedisConsumerName, config.RedisClaimMinIdleSeconds)
+	}
+
+	t.Setenv("REDIS_TRANSPORT", TransportStreams)
+	t.Setenv("REDIS_CONSUMER_NAME", "worker-1")
+	if config, err = LoadConfig(); err != nil || config.RedisTransport != TransportStreams || config.RedisConsumerName != "worker-1" {
+		t.Errorf("LoadConfig() = %+v, %v", config, err)
+	}
+
+	t.Setenv("REDIS_TRANSPORT", "kafka")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("expected an error for an unknown transport")
+	}
+}
+
 func TestLoadProjectConfig_ValidFile(t *testing.T) {
 	projects := []ProjectConfig{
 		{Name: "project-a", WorkingDir: "/path/to/a"},
@@ -129,3 +157,65 @@ func TestLoadProjectConfig_EmptyArray(t *testing.T) {
 		t.Errorf("expected 0 projects, got %d", len(config.Projects))
 	}
 }
+
+func TestProjectConfig_AllowsChannel(t *testing.T) {
+	open := ProjectConfig{Name: "open"}
+	restricted := ProjectConfig{Name: "prod", AllowedChannels: []string{"C1", "C2"}}
+
+	tests := []struct {
+		name    string
+		project ProjectConfig
+		channel string
+		want    bool
+	}{
+		{"no restriction allows any channel", open, "C999", true},
+		{"listed channel allowed", restricted, "C2", true},
+		{"unlisted channel refused", restricted, "C3", false},
+		{"empty channel refused", restricted, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.project.AllowsChannel(tt.channel); got != tt.want {
+				t.Errorf("AllowsChannel(%q) = %v, want %v", tt.channel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectConfig_ValidateServices(t *testing.T) {
+	project := ProjectConfig{Name: "my-project", Services: []string{"web", "worker"}}
+
+	tests := []struct {
+		name     string
+		project  ProjectConfig
+		services []string
+		wantErr  bool
+	}{
+		{"no services targets the whole stack", project, nil, false},
+		{"known services", project, []string{"web", "worker"}, false},
+		{"unknown service", project, []string{"web", "db"}, true},
+		{"project without services", ProjectConfig{Name: "bare"}, []string{"web"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.project.ValidateServices(tt.services)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateServices(%v) error = %v, wantErr %v", tt.services, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetEnvBool(t *testing.T) {
+	t.Setenv("TEST_BOOL_XYZ", "true")
+	if !getEnvBool("TEST_BOOL_XYZ", false) {
+		t.Error("getEnvBool() = false, want true")
+	}
+
+	t.Setenv("TEST_BOOL_XYZ", "maybe")
+	if !getEnvBool("TEST_BOOL_XYZ", true) {
+		t.Error("getEnvBool() with an invalid value should return the default")
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// EmojiWhiteCheckMark confirms a pending destructive command
+	EmojiWhiteCheckMark = "white_check_mark"
+
+	// EventTypeConfirmation marks messages asking the user to confirm a destructive command
+	EventTypeConfirmation = "slack-compose-confirm"
+)
+
+// pendingConfirmation is a destructive command waiting to be confirmed by reaction
+type pendingConfirmation struct {
+	Request   commandRequest
+	ExpiresAt time.Time
+}
+
+// confirmationTimeout returns how long a pending confirmation stays valid
+func (s *Service) confirmationTimeout() time.Duration {
+	if s.config.ConfirmationTimeoutSeconds <= 0 {
+		return DefaultConfirmationTimeoutSeconds * time.Second
+	}
+	return time.Duration(s.config.ConfirmationTimeoutSeconds) * time.Second
+}
+
+// requestConfirmation stores a destructive command and asks the user to confirm it by reaction.
+// The prompt is posted as a top-level message so the confirming reaction can be looked up by its metadata.
+func (s *Service) requestConfirmation(ctx context.Context, req commandRequest) {
+	id, err := newConfirmationID()
+	if err != nil {
+		slog.Error("Failed to create confirmation ID", "request_id", req.ID, "error", err)
+		return
+	}
+
+	timeout := s.confirmationTimeout()
+
+	s.pendingMu.Lock()
+	if s.pending == nil {
+		s.pending = make(map[string]pendingConfirmation)
+	}
+	s.prunePendingLocked(time.Now())
+	s.pending[id] = pendingConfirmation{Request: req, ExpiresAt: time.Now().Add(timeout)}
+	s.pendingMu.Unlock()
+
+	channel := req.SourceChannel
+	if channel == "" {
+		channel = s.config.SlackChannel
+	}
+
+	slackLinerPayload := SlackLinerPayload{
+		Channel: channel,
+		Text: fmt.Sprintf(":warning: <@%s> requested `%s` on project *%s*. React with :%s: on this message within %ds to confirm.",
+			req.UserID, inlineCode(req.Command), escapeMrkdwn(req.Project.Name), EmojiWhiteCheckMark, int(timeout.Seconds())),
+		Metadata: SlackMetadata{
+			EventType: EventTypeConfirmation,
+			EventPayload: map[string]interface{}{
+				"confirmation_id": id,
+				"request_id":      req.ID,
+				"project":         req.Project.Name,
+			},
+		},
+		TTL: int(timeout.Seconds()),
+	}
+
+	if err := s.sendToSlackLiner(ctx, slackLinerPayload); err != nil {
+		slog.Error("Failed to send confirmation request to SlackLiner", "request_id", req.ID, "error", err)
+		return
+	}
+
+	slog.Info("Requested confirmation", "request_id", req.ID, "confirmation_id", id, "command", req.Command, "project", req.Project.Name, "user", req.UserID)
+}
+
+// routeConfirmationReaction finds the confirmation request a reaction was added to and the
+// project it is for, returning the project's worker key and the confirmation
+func (s *Service) routeConfirmationReaction(ctx context.Context, reaction SlackReaction) (string, func(context.Context)) {
+	// ✅ is a common reaction; only ask Slack about the message while a confirmation is pending
+	s.pendingMu.Lock()
+	waiting := len(s.pending)
+	s.pendingMu.Unlock()
+	if waiting == 0 {
+		slog.Debug("No confirmation pending, ignoring reaction", "emoji", reaction.Event.Reaction)
+		return "", nil
+	}
+
+	message, err := s.slackClient.GetMessage(ctx, reaction.Event.Item.Channel, reaction.Event.Item.TS)
+	if err != nil {
+		slog.Error("Failed to retrieve message", "error", err)
+		s.metrics.slackAPIError(SlackMethodGetMessage)
+		return "", nil
+	}
+
+	if message.Metadata.EventType != EventTypeConfirmation {
+		slog.Debug("Message is not a confirmation request, ignoring")
+		return "", nil
+	}
+
+	id, ok := message.Metadata.EventPayload["confirmation_id"].(string)
+	if !ok || id == "" {
+		slog.Warn("No confirmation ID in metadata")
+		return "", nil
+	}
+
+	project, _ := message.Metadata.EventPayload["project"].(string)
+	return projectKey(project), func(ctx context.Context) {
+		s.handleConfirmation(ctx, reaction, id)
+	}
+}
+
+// handleConfirmation dispatches a pending command when its requester confirms it
+func (s *Service) handleConfirmation(ctx context.Context, reaction SlackReaction, id string) {
+	s.pendingMu.Lock()
+	pending, exists := s.pending[id]
+	if exists && pending.Request.UserID == reaction.Event.User {
+		delete(s.pending, id)
+	}
+	s.pendingMu.Unlock()
+
+	if !exists {
+		slog.Info("Confirmation not found or already handled", "confirmation_id", id)
+		s.sendNotice(ctx, reaction.Event.Item.Channel, reaction.Event.Item.TS, ":hourglass: This confirmation has expired or was already handled.")
+		return
+	}
+
+	// Only the user who requested the command may confirm it
+	if pending.Request.UserID != reaction.Event.User {
+		slog.Debug("Ignoring confirmation from another user", "request_id", pending.Request.ID, "confirmation_id", id, "user", reaction.Event.User)
+		return
+	}
+
+	if time.Now().After(pending.ExpiresAt) {
+		slog.Info("Confirmation expired", "request_id", pending.Request.ID, "confirmation_id", id)
+		s.sendNotice(ctx, reaction.Event.Item.Channel, reaction.Event.Item.TS, ":hourglass: This confirmation has expired or was already handled.")
+		return
+	}
+
+	slog.Info("Command confirmed", "request_id", pending.Request.ID, "confirmation_id", id, "command", pending.Request.Command, "project", pending.Request.Project.Name)
+	s.dispatch(ctx, pending.Request)
+}
+
+// prunePendingLocked removes expired confirmations; the caller must hold pendingMu
+func (s *Service) prunePendingLocked(now time.Time) {
+	for id, pending := range s.pending {
+		if now.After(pending.ExpiresAt) {
+			delete(s.pending, id)
+		}
+	}
+}
+
+// confirmationObject returns the Block Kit confirm dialog for a destructive button
+func confirmationObject(label, command string) *slack.ConfirmationBlockObject {
+	confirm := slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject(slack.PlainTextType, "Are you sure?", false, false),
+		slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s* runs `%s` for the selected project.", label, command), false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, label, true, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+	)
+	confirm.Style = slack.StyleDanger
+	return confirm
+}
+
+// newConfirmationID returns a random identifier for a pending confirmation
+func newConfirmationID() (string, error) {
+	return randomID(8)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// confirmReaction builds a reaction payload for the given user and emoji
+func confirmReaction(user, emoji string) string {
+	reaction := SlackReaction{
+		Event: SlackReactionEvent{
+			User:     user,
+			Reaction: emoji,
+			Item:     SlackReactionItem{Channel: "C123", TS: "111.222"},
+		},
+	}
+	data, _ := json.Marshal(reaction)
+	return string(data)
+}
+
+func TestHandleReaction_DestructiveRequestsConfirmation(t *testing.T) {
+	rc := &mockRedisClient{}
+	sc := &mockSlackClient{
+		message: &SlackMessage{
+			Metadata: SlackMetadata{
+				EventType:    "slack-compose",
+				EventPayload: map[string]interface{}{"project": "my-project"},
+			},
+		},
+	}
+	svc := newTestService(rc, sc)
+
+	svc.handleReaction(context.Background(), confirmReaction("U1", EmojiDownArrow))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push (confirmation request), got %d", len(rc.pushed))
+	}
+	if rc.pushed[0].key != "slack_messages" {
+		t.Fatalf("key = %q, want %q", rc.pushed[0].key, "slack_messages")
+	}
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+	if slp.Metadata.EventType != EventTypeConfirmation {
+		t.Errorf("EventType = %q, want %q", slp.Metadata.EventType, EventTypeConfirmation)
+	}
+	if slp.TTL != DefaultConfirmationTimeoutSeconds {
+		t.Errorf("TTL = %d, want %d", slp.TTL, DefaultConfirmationTimeoutSeconds)
+	}
+	if len(svc.pending) != 1 {
+		t.Fatalf("expected 1 pending confirmation, got %d", len(svc.pending))
+	}
+	// The request ID is created before confirmation, so the prompt and the command share it
+	for _, pending := range svc.pending {
+		if pending.Request.ID == "" || slp.Metadata.EventPayload["request_id"] != pending.Request.ID {
+			t.Errorf("request_id = %v, want the pending request's ID %q", slp.Metadata.EventPayload["request_id"], pending.Request.ID)
+		}
+	}
+}
+
+func TestHandleReaction_ConfirmationDispatchesCommand(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+	svc.pending = map[string]pendingConfirmation{
+		"abc": {
+			Request: commandRequest{
+				ID:       "req-1",
+				Project:  svc.config.Projects["my-project"],
+				Command:  "docker compose down",
+				UserID:   "U1",
+				Channel:  "C123",
+				ThreadTS: "100.000",
+			},
+			ExpiresAt: time.Now().Add(time.Minute),
+		},
+	}
+	svc.slackClient = &mockSlackClient{
+		message: &SlackMessage{
+			Metadata: SlackMetadata{
+				EventType:    EventTypeConfirmation,
+				EventPayload: map[string]interface{}{"confirmation_id": "abc"},
+			},
+		},
+	}
+
+	// Another user's confirmation is ignored
+	svc.handleReaction(context.Background(), confirmReaction("U2", EmojiWhiteCheckMark))
+	if len(rc.pushed) != 0 {
+		t.Fatalf("expected 0 pushes for another user's confirmation, got %d", len(rc.pushed))
+	}
+
+	svc.handleReaction(context.Background(), confirmReaction("U1", EmojiWhiteCheckMark))
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(rc.pushed))
+	}
+	if rc.pushed[0].key != "poppit:notifications" {
+		t.Errorf("key = %q, want %q", rc.pushed[0].key, "poppit:notifications")
+	}
+	var pp PoppitPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &pp)
+	if pp.Commands[0] != "docker compose down" {
+		t.Errorf("command = %q, want %q", pp.Commands[0], "docker compose down")
+	}
+	if pp.Metadata["thread_ts"] != "100.000" {
+		t.Errorf("thread_ts = %v, want %q", pp.Metadata["thread_ts"], "100.000")
+	}
+	if pp.Metadata["request_id"] != "req-1" {
+		t.Errorf("request_id = %v, want the confirmed request's ID", pp.Metadata["request_id"])
+	}
+	if len(svc.pending) != 0 {
+		t.Errorf("expected pending confirmation to be removed, got %d", len(svc.pending))
+	}
+}
+
+func TestHandleReaction_ExpiredConfirmation_SendsNotice(t *testing.T) {
+	rc := &mockRedisClient{}
+	sc := &mockSlackClient{
+		message: &SlackMessage{
+			Metadata: SlackMetadata{
+				EventType:    EventTypeConfirmation,
+				EventPayload: map[string]interface{}{"confirmation_id": "abc"},
+			},
+		},
+	}
+	svc := newTestService(rc, sc)
+	svc.pending = map[string]pendingConfirmation{
+		"abc": {
+			Request:   commandRequest{Project: svc.config.Projects["my-project"], Command: "docker compose down", UserID: "U1"},
+			ExpiresAt: time.Now().Add(-time.Second),
+		},
+	}
+
+	svc.handleReaction(context.Background(), confirmReaction("U1", EmojiWhiteCheckMark))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push (notice), got %d", len(rc.pushed))
+	}
+	if rc.pushed[0].key != "slack_messages" {
+		t.Errorf("key = %q, want %q", rc.pushed[0].key, "slack_messages")
+	}
+}
+
+func TestHandleReaction_ConfirmationEmojiWithoutPending_SkipsSlack(t *testing.T) {
+	rc := &mockRedisClient{}
+	sc := &mockSlackClient{
+		message: &SlackMessage{
+			Metadata: SlackMetadata{
+				EventType:    EventTypeConfirmation,
+				EventPayload: map[string]interface{}{"confirmation_id": "abc"},
+			},
+		},
+	}
+	svc := newTestService(rc, sc)
+
+	svc.handleReaction(context.Background(), confirmReaction("U1", EmojiWhiteCheckMark))
+
+	if sc.fetches != 0 {
+		t.Errorf("fetched the message %d times, want none while nothing is pending", sc.fetches)
+	}
+	if len(rc.pushed) != 0 {
+		t.Errorf("expected no pushes, got %d", len(rc.pushed))
+	}
+}
+
+func TestActionButton_DestructiveHasConfirm(t *testing.T) {
+	svc := newTestService(nil, nil)
+
+	down, _ := svc.config.ActionByID(ActionDockerDown)
+	if button := svc.actionButton(down); button.Confirm == nil {
+		t.Error("down button should ask for confirmation")
+	}
+	up, _ := svc.config.ActionByID(ActionDockerUp)
+	if button := svc.actionButton(up); button.Confirm != nil {
+		t.Error("up button should not ask for confirmation")
+	}
+
+	// Destructive actions are configurable in the catalog
+	restart := Action{ID: ActionDockerRestart, Name: "restart", Label: "Restart", Command: "docker compose restart", Destructive: true}
+	if button := svc.actionButton(restart); button.Confirm == nil || button.Confirm.Style != slack.StyleDanger {
+		t.Error("restart button should ask for confirmation when configured as destructive")
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RefOverrideRole is the least role allowed to pass --ref, as Poppit checks the project out at the ref
+const RefOverrideRole = RoleOperator
+
+// gitRefPattern restricts branch and ref names to characters git allows and that need no quoting
+var gitRefPattern = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+
+// gitRef returns the git ref Poppit should use: the override, the project's branch or the default branch
+func (r commandRequest) gitRef() string {
+	switch {
+	case r.Ref != "":
+		return qualifyGitRef(r.Ref)
+	case r.Project.Branch != "":
+		return qualifyGitRef(r.Project.Branch)
+	default:
+		return DefaultGitBranch
+	}
+}
+
+// requiredRole returns the least role allowed to run the request: the action's role, raised to
+// RefOverrideRole when the request overrides the git ref
+func (r commandRequest) requiredRole() Role {
+	required := r.Action.RequiredRole()
+	if r.Ref != "" && !required.Allows(RefOverrideRole) {
+		return RefOverrideRole
+	}
+	return required
+}
+
+// qualifyGitRef turns a branch name into a full ref, leaving full refs (refs/...) unchanged
+func qualifyGitRef(ref string) string {
+	if strings.HasPrefix(ref, "refs/") {
+		return ref
+	}
+	return "refs/heads/" + ref
+}
+
+// validGitRef reports whether a branch or ref name is safe to pass on: only git's usual
+// characters, without "..", a leading "-" or "/", or a trailing "/" or ".lock"
+func validGitRef(ref string) bool {
+	return gitRefPattern.MatchString(ref) &&
+		!strings.Contains(ref, "..") &&
+		!strings.HasPrefix(ref, "-") &&
+		!strings.HasPrefix(ref, "/") &&
+		!strings.HasSuffix(ref, "/") &&
+		!strings.HasSuffix(ref, ".lock")
+}
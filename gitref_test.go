@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCommandRequest_GitRef(t *testing.T) {
+	tests := []struct {
+		name string
+		req  commandRequest
+		want string
+	}{
+		{"default branch", commandRequest{}, DefaultGitBranch},
+		{"project branch", commandRequest{Project: ProjectConfig{Branch: "release"}}, "refs/heads/release"},
+		{"project full ref", commandRequest{Project: ProjectConfig{Branch: "refs/tags/v1.2.0"}}, "refs/tags/v1.2.0"},
+		{"override", commandRequest{Project: ProjectConfig{Branch: "release"}, Ref: "feature/x"}, "refs/heads/feature/x"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.req.gitRef(); got != tt.want {
+			t.Errorf("%s: gitRef() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidGitRef(t *testing.T) {
+	for _, ref := range []string{"main", "release/2026.10", "refs/tags/v1.0.0", "prod_1"} {
+		if !validGitRef(ref) {
+			t.Errorf("validGitRef(%q) = false, want true", ref)
+		}
+	}
+	for _, ref := range []string{"", "-main", "a..b", "main/", "/main", "main.lock", "main branch", "main;rm"} {
+		if validGitRef(ref) {
+			t.Errorf("validGitRef(%q) = true, want false", ref)
+		}
+	}
+}
+
+func TestHandleCommand_Branch(t *testing.T) {
+	tests := []struct {
+		name       string
+		project    ProjectConfig
+		text       string
+		wantBranch string
+	}{
+		{"project branch", ProjectConfig{Name: "my-project", WorkingDir: "/srv/my-project", Branch: "master"}, "my-project", "refs/heads/master"},
+		{"ref override", ProjectConfig{Name: "my-project", WorkingDir: "/srv/my-project", Branch: "master", AllowRefOverride: true}, "my-project up --ref release", "refs/heads/release"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &mockRedisClient{}
+			svc := newTestService(rc, nil)
+			svc.config.Projects["my-project"] = tt.project
+
+			sendCommand(svc, tt.text)
+
+			if len(rc.pushed) != 1 || rc.pushed[0].key != "poppit:notifications" {
+				t.Fatalf("expected 1 push to Poppit, got %+v", rc.pushed)
+			}
+			var pp PoppitPayload
+			json.Unmarshal(rc.pushed[0].value.([]byte), &pp)
+			if pp.Branch != tt.wantBranch {
+				t.Errorf("Branch = %q, want %q", pp.Branch, tt.wantBranch)
+			}
+		})
+	}
+}
+
+func TestHandleCommand_RefOverrideNotAllowed(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	sendCommand(svc, "my-project up --ref release")
+
+	if len(rc.pushed) != 1 || rc.pushed[0].key != "slack_messages" {
+		t.Fatalf("expected 1 notice and nothing sent to Poppit, got %+v", rc.pushed)
+	}
+}
+
+func TestHandleCommand_RefOverrideRequiresOperator(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       Role
+		wantPoppit bool
+	}{
+		{"viewer", RoleViewer, false},
+		{"operator", RoleOperator, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &mockRedisClient{}
+			svc := newTestService(rc, nil)
+			svc.config.Projects["my-project"] = ProjectConfig{Name: "my-project", WorkingDir: "/srv/my-project",
+				AllowRefOverride: true, Roles: map[string]Role{"U1": tt.role}}
+
+			// ps alone only needs viewer, but --ref moves the project's checkout
+			sendCommand(svc, "my-project ps --ref other-branch")
+
+			if len(rc.pushed) != 1 {
+				t.Fatalf("expected 1 push, got %d", len(rc.pushed))
+			}
+			if got := rc.pushed[0].key == "poppit:notifications"; got != tt.wantPoppit {
+				t.Errorf("sent to Poppit = %v, want %v", got, tt.wantPoppit)
+			}
+		})
+	}
+}
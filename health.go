@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// healthPingTimeout bounds the Redis ping made by /readyz and /status
+	healthPingTimeout = 2 * time.Second
+	// httpShutdownTimeout is how long in-flight HTTP requests get when the service stops
+	httpShutdownTimeout = 5 * time.Second
+)
+
+// listenerStatus is the state of one event listener as reported by /status
+type listenerStatus struct {
+	Name          string     `json:"name"`
+	Channel       string     `json:"channel"`
+	Subscribed    bool       `json:"subscribed"`
+	Messages      int64      `json:"messages"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
+	Reconnects    int64      `json:"reconnects"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+// listenerStats tracks whether each listener is subscribed and the messages it has received.
+// The zero value is ready to use.
+type listenerStats struct {
+	mu        sync.Mutex
+	listeners map[string]*listenerStatus
+}
+
+// register adds a listener, resetting it if it was already known
+func (l *listenerStats) register(name, channel string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.listeners == nil {
+		l.listeners = make(map[string]*listenerStatus)
+	}
+	l.listeners[name] = &listenerStatus{Name: name, Channel: channel}
+}
+
+// setSubscribed records whether the listener is currently receiving events.
+// Subscribing clears the error that caused the last reconnect.
+func (l *listenerStats) setSubscribed(name string, subscribed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if status, ok := l.listeners[name]; ok {
+		status.Subscribed = subscribed
+		if subscribed {
+			status.LastError = ""
+		}
+	}
+}
+
+// reconnecting records that the listener lost its subscription and is trying to restore it
+func (l *listenerStats) reconnecting(name string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if status, ok := l.listeners[name]; ok {
+		status.Subscribed = false
+		status.Reconnects++
+		status.LastError = err.Error()
+	}
+}
+
+// received counts a message delivered to the listener
+func (l *listenerStats) received(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if status, ok := l.listeners[name]; ok {
+		now := time.Now().UTC()
+		status.Messages++
+		status.LastMessageAt = &now
+	}
+}
+
+// snapshot returns a copy of every listener's status, sorted by name
+func (l *listenerStats) snapshot() []listenerStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	statuses := make([]listenerStatus, 0, len(l.listeners))
+	for _, status := range l.listeners {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// statusReport is the body of /status
+type statusReport struct {
+	Ready         bool             `json:"ready"`
+	Problems      []string         `json:"problems,omitempty"`
+	StartedAt     time.Time        `json:"started_at"`
+	UptimeSeconds int64            `json:"uptime_seconds"`
+	Transport     string           `json:"transport"`
+	Listeners     []listenerStatus `json:"listeners"`
+	Workers       *workerStats     `json:"workers,omitempty"`
+}
+
+// readiness checks that Redis responds and every listener is subscribed, returning what is wrong
+func (s *Service) readiness(ctx context.Context, listeners []listenerStatus) []string {
+	var problems []string
+
+	pingCtx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+	defer cancel()
+	if err := s.redisClient.Ping(pingCtx); err != nil {
+		problems = append(problems, fmt.Sprintf("redis: %v", err))
+	}
+
+	if len(listeners) == 0 {
+		problems = append(problems, "no listeners started")
+	}
+	for _, listener := range listeners {
+		switch {
+		case listener.Subscribed:
+		case listener.LastError != "":
+			problems = append(problems, fmt.Sprintf("listener %s is not subscribed to %s, reconnecting after: %s",
+				listener.Name, listener.Channel, listener.LastError))
+		default:
+			problems = append(problems, fmt.Sprintf("listener %s is not subscribed to %s", listener.Name, listener.Channel))
+		}
+	}
+	return problems
+}
+
+// healthHandler serves the health endpoints: /healthz (process liveness),
+// /readyz (Redis and listeners), /status (JSON details) and /metrics (Prometheus)
+func (s *Service) healthHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if problems := s.readiness(r.Context(), s.listeners.snapshot()); len(problems) > 0 {
+			http.Error(w, strings.Join(problems, "\n"), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ready")
+	})
+
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		listeners := s.listeners.snapshot()
+		problems := s.readiness(r.Context(), listeners)
+		report := statusReport{
+			Ready:         len(problems) == 0,
+			Problems:      problems,
+			StartedAt:     s.startedAt,
+			UptimeSeconds: int64(time.Since(s.startedAt).Seconds()),
+			Transport:     s.config.RedisTransport,
+			Listeners:     listeners,
+		}
+		if s.workers != nil {
+			stats := s.workers.stats()
+			report.Workers = &stats
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if !report.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+
+	if s.metrics != nil {
+		mux.Handle("GET /metrics", s.metrics.handler())
+	}
+
+	return mux
+}
+
+// startHTTPServer listens on HTTP_ADDR and serves the health endpoints until the context is cancelled.
+// The address is bound before returning so a bad address fails startup.
+func (s *Service) startHTTPServer(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.HTTPAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.HTTPAddr, err)
+	}
+
+	server := &http.Server{
+		Handler:           s.healthHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		slog.Info("Serving health endpoints", "address", listener.Addr().String())
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP server failed", "error", err)
+		}
+	}()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Failed to shut down HTTP server", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// runHealthcheck queries the running service's health endpoint on HTTP_ADDR, returning the
+// process exit code: 0 when it answers 200, 1 otherwise and 2 for usage errors. The image has
+// no shell or curl, so Docker healthchecks run the binary itself.
+func runHealthcheck(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("healthcheck", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	path := flags.String("path", "/readyz", "endpoint to query")
+	timeout := flags.Duration("timeout", 3*time.Second, "request timeout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: slackcompose healthcheck [-path /readyz] [-timeout 3s]")
+		return 2
+	}
+
+	addr := getEnv("HTTP_ADDR", "")
+	if addr == "" {
+		fmt.Fprintln(stderr, "HTTP_ADDR is not set, so the health endpoints are disabled")
+		return 1
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid HTTP_ADDR %q: %v\n", addr, err)
+		return 1
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	resp, err := client.Get("http://" + net.JoinHostPort(host, port) + *path)
+	if err != nil {
+		fmt.Fprintf(stderr, "health check failed: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(stderr, "%s: %s\n%s", *path, resp.Status, body)
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s", body)
+	return 0
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListenerStats(t *testing.T) {
+	var stats listenerStats
+	stats.register("reactions", "slack-reactions")
+	stats.register("commands", "slack-commands")
+	stats.setSubscribed("commands", true)
+	stats.received("commands")
+	stats.received("commands")
+	stats.received("unknown")
+
+	got := stats.snapshot()
+	if len(got) != 2 || got[0].Name != "commands" || got[1].Name != "reactions" {
+		t.Fatalf("snapshot = %+v", got)
+	}
+	if !got[0].Subscribed || got[0].Messages != 2 || got[0].LastMessageAt == nil {
+		t.Errorf("commands = %+v", got[0])
+	}
+	if got[1].Subscribed || got[1].Messages != 0 || got[1].LastMessageAt != nil {
+		t.Errorf("reactions = %+v", got[1])
+	}
+}
+
+// healthService returns a service with two listeners, both subscribed
+func healthService(rc *mockRedisClient) *Service {
+	svc := newTestService(rc, nil)
+	svc.config.RedisTransport = TransportPubSub
+	svc.startedAt = time.Now().Add(-time.Minute)
+	for _, name := range []string{"commands", "reactions"} {
+		svc.listeners.register(name, "slack-"+name)
+		svc.listeners.setSubscribed(name, true)
+	}
+	return svc
+}
+
+func getHealth(svc *Service, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	svc.healthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestHealthz(t *testing.T) {
+	// Liveness does not depend on Redis
+	svc := healthService(&mockRedisClient{pingErr: errors.New("connection refused")})
+	if rec := getHealth(svc, "/healthz"); rec.Code != http.StatusOK {
+		t.Errorf("/healthz = %d, want 200", rec.Code)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := healthService(rc)
+	if rec := getHealth(svc, "/readyz"); rec.Code != http.StatusOK {
+		t.Errorf("/readyz = %d (%s), want 200", rec.Code, rec.Body)
+	}
+
+	svc.listeners.setSubscribed("reactions", false)
+	rec := getHealth(svc, "/readyz")
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "listener reactions is not subscribed") {
+		t.Errorf("/readyz with an unsubscribed listener = %d (%s)", rec.Code, rec.Body)
+	}
+
+	svc.listeners.reconnecting("reactions", errSubscriptionClosed)
+	rec = getHealth(svc, "/readyz")
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "reconnecting after: subscription closed") {
+		t.Errorf("/readyz with a reconnecting listener = %d (%s)", rec.Code, rec.Body)
+	}
+
+	svc.listeners.setSubscribed("reactions", true)
+	rc.pingErr = errors.New("connection refused")
+	rec = getHealth(svc, "/readyz")
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "redis: connection refused") {
+		t.Errorf("/readyz with Redis down = %d (%s)", rec.Code, rec.Body)
+	}
+}
+
+func TestReadyz_NoListeners(t *testing.T) {
+	svc := newTestService(nil, nil)
+	if rec := getHealth(svc, "/readyz"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz before any listener started = %d, want 503", rec.Code)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	svc := healthService(&mockRedisClient{})
+	svc.listeners.received("commands")
+
+	rec := getHealth(svc, "/status")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/status = %d, want 200", rec.Code)
+	}
+	var report statusReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !report.Ready || report.Transport != TransportPubSub || report.UptimeSeconds < 60 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Listeners) != 2 || report.Listeners[0].Messages != 1 || report.Listeners[0].LastMessageAt == nil {
+		t.Errorf("listeners = %+v", report.Listeners)
+	}
+}
+
+func TestRunHealthcheck(t *testing.T) {
+	svc := healthService(&mockRedisClient{})
+	server := httptest.NewServer(svc.healthHandler())
+	defer server.Close()
+	t.Setenv("HTTP_ADDR", strings.TrimPrefix(server.URL, "http://"))
+
+	var stdout, stderr bytes.Buffer
+	if code := runHealthcheck(nil, &stdout, &stderr); code != 0 {
+		t.Errorf("healthcheck = %d (%s), want 0", code, stderr.String())
+	}
+
+	svc.listeners.setSubscribed("commands", false)
+	if code := runHealthcheck(nil, &stdout, &stderr); code != 1 {
+		t.Errorf("healthcheck of an unready service = %d, want 1", code)
+	}
+	if code := runHealthcheck([]string{"-path", "/healthz"}, &stdout, &stderr); code != 0 {
+		t.Errorf("liveness healthcheck = %d, want 0", code)
+	}
+}
+
+func TestRunHealthcheck_Disabled(t *testing.T) {
+	t.Setenv("HTTP_ADDR", "")
+	var stdout, stderr bytes.Buffer
+	if code := runHealthcheck(nil, &stdout, &stderr); code != 1 {
+		t.Errorf("healthcheck without HTTP_ADDR = %d, want 1", code)
+	}
+}
+
+func TestStartHTTPServer_BadAddress(t *testing.T) {
+	svc := newTestService(nil, nil)
+	svc.config.HTTPAddr = "not-an-address"
+	if err := svc.startHTTPServer(t.Context()); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+}
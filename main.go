@@ -6,9 +6,15 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func main() {
+	// Command-line subcommands such as "config check" run instead of the service
+	if exitCode, handled := runCLI(os.Args[1:], os.Stdout, os.Stderr); handled {
+		os.Exit(exitCode)
+	}
+
 	// Initialize logger
 	initLogger()
 
@@ -29,10 +35,16 @@ func main() {
 		slog.Error("Failed to create Redis client", "error", err)
 		os.Exit(1)
 	}
-	defer redisClient.Close()
+
+	// Create audit log sinks
+	auditSink, err := NewAuditSink(config, redisClient)
+	if err != nil {
+		slog.Error("Failed to create audit log", "error", err)
+		os.Exit(1)
+	}
 
 	// Create service
-	service := NewService(config, redisClient)
+	service := NewService(config, redisClient, auditSink)
 
 	// Start service
 	ctx, cancel := context.WithCancel(context.Background())
@@ -43,13 +55,42 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Wait for interrupt signal
+	// Wait for interrupt signal, reloading the project config on SIGHUP
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	<-sigChan
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+
+wait:
+	for {
+		select {
+		case <-hupChan:
+			slog.Info("Received SIGHUP, reloading project config")
+			service.ReloadProjectConfig(ctx, "SIGHUP")
+		case <-sigChan:
+			break wait
+		}
+	}
 
-	slog.Info("Shutting down SlackCompose service...")
+	// Stop accepting events, then let in-flight handlers finish their pushes before closing Redis
+	slog.Info("Shutting down SlackCompose service...", "timeout_seconds", config.ShutdownTimeoutSeconds)
 	cancel()
+
+	// A second signal skips the drain
+	go func() {
+		<-sigChan
+		slog.Warn("Received second signal, exiting without draining")
+		os.Exit(1)
+	}()
+
+	service.Shutdown(time.Duration(config.ShutdownTimeoutSeconds) * time.Second)
+
+	if err := auditSink.Close(); err != nil {
+		slog.Error("Failed to close audit log", "error", err)
+	}
+	if err := redisClient.Close(); err != nil {
+		slog.Error("Failed to close Redis client", "error", err)
+	}
 }
 
 // initLogger initializes the structured logger with the configured level
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsNamespace prefixes every metric name
+const metricsNamespace = "slackcompose"
+
+// Reasons an incoming event is ignored, used as the reason label of events_ignored_total
+const (
+	IgnoreReasonParseError       = "parse_error"
+	IgnoreReasonUnsupportedEmoji = "unsupported_emoji"
+	IgnoreReasonUnknownProject   = "unknown_project"
+	IgnoreReasonNonButtonAction  = "non_button_action"
+	IgnoreReasonQueueFull        = "queue_full"
+)
+
+// Slack API methods, used as the method label of slack_api_errors_total
+const (
+	SlackMethodGetMessage = "get_message"
+	SlackMethodUploadFile = "upload_file"
+)
+
+// metrics holds the Prometheus collectors exposed on /metrics.
+// A nil *metrics records nothing, so services built without one (as in tests) need no checks.
+type metrics struct {
+	registry *prometheus.Registry
+
+	eventsReceived   *prometheus.CounterVec
+	eventsIgnored    *prometheus.CounterVec
+	dispatches       *prometheus.CounterVec
+	slackLinerPosts  prometheus.Counter
+	slackAPIErrors   *prometheus.CounterVec
+	rpushFailures    *prometheus.CounterVec
+	commandRoundTrip *prometheus.HistogramVec
+	reconnects       *prometheus.CounterVec
+}
+
+// newMetrics creates the collectors in their own registry, along with the Go runtime and process collectors
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		eventsReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "events_received_total",
+			Help:      "Events received, by Redis channel or stream.",
+		}, []string{"channel"}),
+		eventsIgnored: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "events_ignored_total",
+			Help:      "Events ignored, by reason.",
+		}, []string{"reason"}),
+		dispatches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "poppit_dispatches_total",
+			Help:      "Commands sent to Poppit, by project and action.",
+		}, []string{"project", "action"}),
+		slackLinerPosts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "slackliner_posts_total",
+			Help:      "Messages queued for SlackLiner.",
+		}),
+		slackAPIErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "slack_api_errors_total",
+			Help:      "Failed Slack API calls, by method.",
+		}, []string{"method"}),
+		rpushFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "redis_rpush_failures_total",
+			Help:      "Failed pushes to Redis lists, by list.",
+		}, []string{"list"}),
+		commandRoundTrip: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "command_round_trip_seconds",
+			Help:      "Time from sending a command to Poppit until its output arrives, by project and action.",
+			Buckets:   []float64{0.5, 1, 2, 5, 10, 30, 60, 120, 300, 600},
+		}, []string{"project", "action"}),
+		reconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "listener_reconnects_total",
+			Help:      "Attempts to restore a lost Redis subscription, by listener.",
+		}, []string{"listener"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.eventsReceived,
+		m.eventsIgnored,
+		m.dispatches,
+		m.slackLinerPosts,
+		m.slackAPIErrors,
+		m.rpushFailures,
+		m.commandRoundTrip,
+		m.reconnects,
+	)
+	return m
+}
+
+// handler serves the metrics in the Prometheus text format
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// eventReceived counts an event received on a channel
+func (m *metrics) eventReceived(channel string) {
+	if m != nil {
+		m.eventsReceived.WithLabelValues(channel).Inc()
+	}
+}
+
+// eventIgnored counts an event dropped for the given reason
+func (m *metrics) eventIgnored(reason string) {
+	if m != nil {
+		m.eventsIgnored.WithLabelValues(reason).Inc()
+	}
+}
+
+// dispatched counts a command sent to Poppit
+func (m *metrics) dispatched(project, action string) {
+	if m != nil {
+		m.dispatches.WithLabelValues(project, action).Inc()
+	}
+}
+
+// slackLinerPosted counts a message queued for SlackLiner
+func (m *metrics) slackLinerPosted() {
+	if m != nil {
+		m.slackLinerPosts.Inc()
+	}
+}
+
+// slackAPIError counts a failed Slack API call
+func (m *metrics) slackAPIError(method string) {
+	if m != nil {
+		m.slackAPIErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// rpushFailed counts a failed push to a Redis list
+func (m *metrics) rpushFailed(list string) {
+	if m != nil {
+		m.rpushFailures.WithLabelValues(list).Inc()
+	}
+}
+
+// roundTrip records the time a command took from dispatch to output
+func (m *metrics) roundTrip(project, action string, latency time.Duration) {
+	if m != nil {
+		m.commandRoundTrip.WithLabelValues(project, action).Observe(latency.Seconds())
+	}
+}
+
+// listenerReconnected counts an attempt to restore a listener's subscription
+func (m *metrics) listenerReconnected(listener string) {
+	if m != nil {
+		m.reconnects.WithLabelValues(listener).Inc()
+	}
+}
+
+// observeWorkers exposes the worker pool's size, queue depth, busy workers and full queues
+func (m *metrics) observeWorkers(p *workerPool) {
+	if m == nil || p == nil {
+		return
+	}
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "workers",
+			Help:      "Workers handling events.",
+		}, func() float64 { return float64(len(p.queues)) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "workers_busy",
+			Help:      "Workers currently handling an event; saturated when equal to slackcompose_workers.",
+		}, func() float64 { return float64(p.busy.Load()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "worker_queue_depth",
+			Help:      "Events waiting for a worker.",
+		}, func() float64 { return float64(p.queued.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "worker_queue_full_total",
+			Help:      "Events that found their worker's queue full.",
+		}, func() float64 { return float64(p.full.Load()) }),
+	)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func metricsService(rc *mockRedisClient, sc SlackClientInterface) *Service {
+	svc := newTestService(rc, sc)
+	svc.metrics = newMetrics()
+	return svc
+}
+
+func TestMetrics_NilRecordsNothing(t *testing.T) {
+	var m *metrics
+	m.eventReceived("c")
+	m.eventIgnored(IgnoreReasonParseError)
+	m.dispatched("p", "a")
+	m.slackLinerPosted()
+	m.slackAPIError(SlackMethodGetMessage)
+	m.rpushFailed("l")
+	m.roundTrip("p", "a", time.Second)
+}
+
+func TestMetrics_IgnoredEvents(t *testing.T) {
+	sc := &mockSlackClient{err: errors.New("channel_not_found")}
+	svc := metricsService(nil, sc)
+	ctx := context.Background()
+
+	svc.handleCommand(ctx, "not json")
+	svc.handleReaction(ctx, `{"event":{"reaction":"tada"}}`)
+	svc.handleReaction(ctx, `{"event":{"reaction":"arrow_up"}}`)
+	svc.handleBlockAction(ctx, `{"state":{"values":{"project_block":{"SlackCompose":{"selected_option":{"value":"nope"}}}}}}`)
+	svc.handleBlockAction(ctx, `{"actions":[{"type":"static_select"}],"state":{"values":{"project_block":{"SlackCompose":{"selected_option":{"value":"my-project"}}}}}}`)
+
+	for reason, want := range map[string]float64{
+		IgnoreReasonParseError:       1,
+		IgnoreReasonUnsupportedEmoji: 1,
+		IgnoreReasonUnknownProject:   1,
+		IgnoreReasonNonButtonAction:  1,
+	} {
+		if got := testutil.ToFloat64(svc.metrics.eventsIgnored.WithLabelValues(reason)); got != want {
+			t.Errorf("events ignored for %s = %v, want %v", reason, got, want)
+		}
+	}
+	if got := testutil.ToFloat64(svc.metrics.slackAPIErrors.WithLabelValues(SlackMethodGetMessage)); got != 1 {
+		t.Errorf("GetMessage errors = %v, want 1", got)
+	}
+}
+
+func TestMetrics_DispatchAndRoundTrip(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := metricsService(rc, nil)
+	ctx := context.Background()
+
+	sendCommand(svc, "my-project restart web")
+	if got := testutil.ToFloat64(svc.metrics.dispatches.WithLabelValues("my-project", "restart")); got != 1 {
+		t.Fatalf("dispatches = %v, want 1", got)
+	}
+
+	var pp PoppitPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &pp)
+	data, _ := json.Marshal(PoppitCommandOutput{Type: "slack-compose", Command: pp.Commands[0], Metadata: pp.Metadata})
+	svc.handlePoppitOutput(ctx, string(data))
+
+	if got := testutil.CollectAndCount(svc.metrics.commandRoundTrip); got != 1 {
+		t.Errorf("round trip series = %d, want 1", got)
+	}
+	if got := testutil.ToFloat64(svc.metrics.slackLinerPosts); got != 1 {
+		t.Errorf("SlackLiner posts = %v, want 1", got)
+	}
+}
+
+func TestMetrics_RPushFailures(t *testing.T) {
+	svc := metricsService(&mockRedisClient{pushErr: errors.New("READONLY")}, nil)
+
+	sendCommand(svc, "my-project restart web")
+
+	if got := testutil.ToFloat64(svc.metrics.rpushFailures.WithLabelValues("poppit:notifications")); got != 1 {
+		t.Errorf("Poppit push failures = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(svc.metrics.dispatches.WithLabelValues("my-project", "restart")); got != 0 {
+		t.Errorf("failed pushes should not count as dispatches, got %v", got)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	svc := healthService(&mockRedisClient{})
+	svc.metrics = newMetrics()
+	svc.metrics.eventReceived("slack-commands")
+
+	rec := getHealth(svc, "/metrics")
+	if rec.Code != 200 {
+		t.Fatalf("/metrics = %d, want 200", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `slackcompose_events_received_total{channel="slack-commands"} 1`) {
+		t.Errorf("/metrics does not include the events counter:\n%s", body)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// projectFile is the document form of a project config file: settings shared by every
+// project under defaults, and the projects themselves, each of which may override them
+type projectFile struct {
+	Defaults ProjectConfig   `json:"defaults" yaml:"defaults" toml:"defaults"`
+	Projects []ProjectConfig `json:"projects" yaml:"projects" toml:"projects"`
+}
+
+// parseProjectConfig decodes the projects in a project config file, choosing the format
+// from the file extension (.yaml/.yml, .toml, otherwise JSON) and applying the defaults block.
+// JSON files may be a plain array of projects or a document with defaults.
+func parseProjectConfig(path string, data []byte) ([]ProjectConfig, error) {
+	var file projectFile
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&file); err != nil {
+			return nil, fmt.Errorf("failed to parse project config: %w", err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), &file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse project config: %w", err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("failed to parse project config: unknown keys %v", undecoded)
+		}
+	default:
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			if err := json.Unmarshal(data, &file); err != nil {
+				return nil, fmt.Errorf("failed to parse project config: %w", err)
+			}
+		} else if err := json.Unmarshal(data, &file.Projects); err != nil {
+			return nil, fmt.Errorf("failed to parse project config: %w", err)
+		}
+	}
+
+	if file.Defaults.Name != "" || file.Defaults.WorkingDir != "" || file.Defaults.ProjectName != "" || file.Defaults.AllowRefOverride {
+		return nil, fmt.Errorf("failed to parse project config: defaults cannot set name, working_dir, project_name or allow_ref_override")
+	}
+
+	projects := make([]ProjectConfig, len(file.Projects))
+	for i, project := range file.Projects {
+		projects[i] = project.withDefaults(file.Defaults)
+	}
+	return projects, nil
+}
+
+// withDefaults fills the settings the project leaves unset from the defaults.
+// Roles are merged, with the project's assignments taking precedence, and
+// redaction patterns from the defaults apply in addition to the project's own.
+func (p ProjectConfig) withDefaults(defaults ProjectConfig) ProjectConfig {
+	if p.AllowedChannels == nil {
+		p.AllowedChannels = defaults.AllowedChannels
+	}
+	if p.Services == nil {
+		p.Services = defaults.Services
+	}
+	if p.ComposeFiles == nil {
+		p.ComposeFiles = defaults.ComposeFiles
+	}
+	if p.Profiles == nil {
+		p.Profiles = defaults.Profiles
+	}
+	if p.EnvFiles == nil {
+		p.EnvFiles = defaults.EnvFiles
+	}
+	if p.Branch == "" {
+		p.Branch = defaults.Branch
+	}
+	if len(defaults.Redact) > 0 {
+		p.Redact = append(append([]string{}, defaults.Redact...), p.Redact...)
+	}
+
+	if len(defaults.Roles) > 0 {
+		roles := make(map[string]Role, len(defaults.Roles)+len(p.Roles))
+		for userID, role := range defaults.Roles {
+			roles[userID] = role
+		}
+		for userID, role := range p.Roles {
+			roles[userID] = role
+		}
+		p.Roles = roles
+	}
+
+	return p
+}
+
+// sortedKeys returns the keys of a role map in order
+func sortedKeys(roles map[string]Role) []string {
+	keys := make([]string, 0, len(roles))
+	for key := range roles {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
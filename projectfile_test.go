@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// wantProjects is the config described by every example file below
+var wantProjects = map[string]ProjectConfig{
+	"web-stack": {
+		Name:            "web-stack",
+		WorkingDir:      "/srv/web-stack",
+		AllowedChannels: []string{"C1"},
+		Roles:           map[string]Role{"U1": RoleAdmin, "U2": RoleOperator},
+		Services:        []string{"web"},
+	},
+	"prod-stack": {
+		Name:            "prod-stack",
+		WorkingDir:      "/srv/prod-stack",
+		AllowedChannels: []string{"CPROD"},
+		Roles:           map[string]Role{"U1": RoleViewer},
+	},
+}
+
+func loadProjectFile(t *testing.T, name, content string) (map[string]ProjectConfig, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return readProjectConfig(path)
+}
+
+func TestReadProjectConfig_Formats(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+	}{
+		{"projects.yaml", `
+defaults:
+  allowed_channels: [C1]
+  roles:
+    U1: admin
+projects:
+  - name: web-stack
+    working_dir: /srv/web-stack
+    services: [web]
+    roles:
+      U2: operator
+  - name: prod-stack
+    working_dir: /srv/prod-stack
+    allowed_channels: [CPROD]
+    roles:
+      U1: viewer
+`},
+		{"projects.toml", `
+[defaults]
+allowed_channels = ["C1"]
+roles = { U1 = "admin" }
+
+[[projects]]
+name = "web-stack"
+working_dir = "/srv/web-stack"
+services = ["web"]
+roles = { U2 = "operator" }
+
+[[projects]]
+name = "prod-stack"
+working_dir = "/srv/prod-stack"
+allowed_channels = ["CPROD"]
+roles = { U1 = "viewer" }
+`},
+		{"projects.json", `{
+  "defaults": {"allowed_channels": ["C1"], "roles": {"U1": "admin"}},
+  "projects": [
+    {"name": "web-stack", "working_dir": "/srv/web-stack", "services": ["web"], "roles": {"U2": "operator"}},
+    {"name": "prod-stack", "working_dir": "/srv/prod-stack", "allowed_channels": ["CPROD"], "roles": {"U1": "viewer"}}
+  ]
+}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			projects, err := loadProjectFile(t, tt.file, tt.content)
+			if err != nil {
+				t.Fatalf("readProjectConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(projects, wantProjects) {
+				t.Errorf("projects = %+v\nwant %+v", projects, wantProjects)
+			}
+		})
+	}
+}
+
+func TestReadProjectConfig_JSONArrayUnchanged(t *testing.T) {
+	projects, err := loadProjectFile(t, "projects.json", `[{"name": "a", "working_dir": "/srv/a"}]`)
+	if err != nil {
+		t.Fatalf("readProjectConfig() error = %v", err)
+	}
+	want := map[string]ProjectConfig{"a": {Name: "a", WorkingDir: "/srv/a"}}
+	if !reflect.DeepEqual(projects, want) {
+		t.Errorf("projects = %+v, want %+v", projects, want)
+	}
+}
+
+func TestReadProjectConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"unknown YAML key", "projects.yml", "projects:\n  - name: a\n    working_dir: /srv/a\n    workdir: /srv/b\n"},
+		{"unknown TOML key", "projects.toml", "[[projects]]\nname = \"a\"\nworking_dir = \"/srv/a\"\nworkdir = \"/srv/b\"\n"},
+		{"defaults with a name", "projects.yaml", "defaults:\n  name: a\nprojects: []\n"},
+		{"invalid project", "projects.yaml", "projects:\n  - name: a\n    working_dir: relative\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadProjectFile(t, tt.file, tt.content); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestReadProjectConfig_RedactDefaultsMerged(t *testing.T) {
+	projects, err := loadProjectFile(t, "projects.yaml", `
+defaults:
+  redact: ['corp-\d+']
+projects:
+  - name: a
+    working_dir: /srv/a
+    redact: ['tenant-\d+']
+  - name: b
+    working_dir: /srv/b
+`)
+	if err != nil {
+		t.Fatalf("readProjectConfig() error = %v", err)
+	}
+	if got := projects["a"].Redact; !reflect.DeepEqual(got, []string{`corp-\d+`, `tenant-\d+`}) {
+		t.Errorf("a redact = %q", got)
+	}
+	if got := projects["b"].Redact; !reflect.DeepEqual(got, []string{`corp-\d+`}) {
+		t.Errorf("b redact = %q", got)
+	}
+}
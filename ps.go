@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// psVerb is the docker compose subcommand whose output is rendered as Block Kit
+	psVerb = "ps"
+
+	// psFormatFlag asks docker compose ps for machine-readable output
+	psFormatFlag = "--format"
+	psFormatJSON = "json"
+
+	// maxPSServiceBlocks keeps the message under Slack's 50 block limit
+	maxPSServiceBlocks = 45
+	// maxSectionTextLength is the longest text Slack accepts in a section block
+	maxSectionTextLength = 3000
+
+	psStderrFormat    = "*Standard Error:*\n```\n%s\n```"
+	psStderrTruncated = "\n_Standard error truncated._"
+)
+
+// psService is one container in the output of docker compose ps --format json
+type psService struct {
+	Name       string        `json:"Name"`
+	Service    string        `json:"Service"`
+	Image      string        `json:"Image"`
+	State      string        `json:"State"`
+	Health     string        `json:"Health"`
+	Status     string        `json:"Status"`
+	Publishers []psPublisher `json:"Publishers"`
+}
+
+// psPublisher is a port mapping of a container
+type psPublisher struct {
+	URL           string `json:"URL"`
+	TargetPort    int    `json:"TargetPort"`
+	PublishedPort int    `json:"PublishedPort"`
+	Protocol      string `json:"Protocol"`
+}
+
+// withPSFormat asks ps commands for JSON output so it can be rendered as Block Kit.
+// Commands that already choose a format are left alone.
+func withPSFormat(command string) string {
+	if composeVerb(command) != psVerb || formatFlag(command) != "" {
+		return command
+	}
+	return command + " " + psFormatFlag + " " + psFormatJSON
+}
+
+// isPSJSON reports whether the command is a ps that produces JSON output
+func isPSJSON(command string) bool {
+	return composeVerb(command) == psVerb && formatFlag(command) == psFormatJSON
+}
+
+// formatFlag returns the value of the command's --format option, if any
+func formatFlag(command string) string {
+	fields := strings.Fields(command)
+	for i, field := range fields {
+		if value, ok := strings.CutPrefix(field, psFormatFlag+"="); ok {
+			return value
+		}
+		if field == psFormatFlag && i+1 < len(fields) {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
+
+// parsePSOutput parses docker compose ps JSON output. Older versions print a JSON
+// array, newer ones print one object per line; empty output means no containers.
+func parsePSOutput(output string) ([]psService, error) {
+	trimmed := strings.TrimSpace(output)
+	if trimmed == "" {
+		return nil, nil
+	}
+
+	var services []psService
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal([]byte(trimmed), &services); err != nil {
+			return nil, fmt.Errorf("failed to parse ps output: %w", err)
+		}
+		return services, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(trimmed)))
+	for {
+		var service psService
+		err := decoder.Decode(&service)
+		if errors.Is(err, io.EOF) {
+			return services, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ps output: %w", err)
+		}
+		services = append(services, service)
+	}
+}
+
+// stateEmoji returns the emoji for a container's state, letting a failing health check win
+func (p psService) stateEmoji() string {
+	switch {
+	case p.Health == "unhealthy":
+		return ":warning:"
+	case p.State == "running" && p.Health == "starting":
+		return ":hourglass_flowing_sand:"
+	case p.State == "running":
+		return ":large_green_circle:"
+	case p.State == "restarting":
+		return ":arrows_counterclockwise:"
+	case p.State == "paused":
+		return ":double_vertical_bar:"
+	case p.State == "exited" || p.State == "dead":
+		return ":red_circle:"
+	default:
+		return ":white_circle:"
+	}
+}
+
+// ports lists the published ports as host→container/protocol, ignoring duplicate IPv4/IPv6 bindings
+func (p psService) ports() []string {
+	seen := make(map[string]bool)
+	var ports []string
+	for _, publisher := range p.Publishers {
+		if publisher.PublishedPort == 0 {
+			continue
+		}
+		port := fmt.Sprintf("%d→%d/%s", publisher.PublishedPort, publisher.TargetPort, publisher.Protocol)
+		if !seen[port] {
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// text describes the service for a section block
+func (p psService) text() string {
+	name := p.Service
+	if name == "" {
+		name = p.Name
+	}
+
+	text := fmt.Sprintf("%s *%s*", p.stateEmoji(), escapeMrkdwn(name))
+	if p.Image != "" {
+		text += fmt.Sprintf("  `%s`", inlineCode(p.Image))
+	}
+	status := p.Status
+	if status == "" {
+		status = p.State
+	}
+	text += "\n" + escapeMrkdwn(status)
+	if ports := p.ports(); len(ports) > 0 {
+		text += " · Ports: " + escapeMrkdwn(strings.Join(ports, ", "))
+	}
+	return text
+}
+
+// psBlocks renders parsed ps output as Block Kit: the header, one section per service and any stderr
+func psBlocks(header string, services []psService, stderr string) []slack.Block {
+	sort.SliceStable(services, func(i, j int) bool { return services[i].Service < services[j].Service })
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, header, false, false), nil, nil),
+	}
+
+	if len(services) == 0 {
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, "No containers.", false, false)))
+	}
+	for i, service := range services {
+		if i == maxPSServiceBlocks {
+			blocks = append(blocks, slack.NewContextBlock("",
+				slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("…and %d more", len(services)-i), false, false)))
+			break
+		}
+		blocks = append(blocks,
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, service.text(), false, false), nil, nil))
+	}
+
+	if stderr != "" {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, psStderrText(stderr), false, false), nil, nil))
+	}
+	return blocks
+}
+
+// psStderrText renders stderr as a code block that fits in one section, keeping its first lines
+func psStderrText(stderr string) string {
+	text := codeBlockText(stderr)
+	limit := maxSectionTextLength - len(fmt.Sprintf(psStderrFormat, "")) - len(psStderrTruncated)
+	if len(text) <= limit {
+		return fmt.Sprintf(psStderrFormat, text)
+	}
+	return fmt.Sprintf(psStderrFormat, truncateText(text, limit)) + psStderrTruncated
+}
+
+// truncateText cuts escaped text to at most limit bytes, at the last line break when there is one.
+// A cut inside a line never splits a character or an HTML entity.
+func truncateText(text string, limit int) string {
+	text = text[:limit]
+	if i := strings.LastIndexByte(text, '\n'); i > 0 {
+		return text[:i]
+	}
+	for !utf8.ValidString(text) {
+		text = text[:len(text)-1]
+	}
+	if i := strings.LastIndexByte(text, '&'); i >= 0 && !strings.Contains(text[i:], ";") {
+		text = text[:i]
+	}
+	return text
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+const psArrayOutput = `[{"Name":"app-web-1","Service":"web","Image":"nginx:1.27","State":"running","Health":"","Status":"Up 2 hours","Publishers":[{"URL":"0.0.0.0","TargetPort":80,"PublishedPort":8080,"Protocol":"tcp"},{"URL":"::","TargetPort":80,"PublishedPort":8080,"Protocol":"tcp"}]},{"Name":"app-worker-1","Service":"worker","Image":"app:latest","State":"exited","Health":"","Status":"Exited (1) 5 minutes ago","Publishers":null}]`
+
+const psLinesOutput = `{"Name":"app-web-1","Service":"web","Image":"nginx:1.27","State":"running","Health":"unhealthy","Status":"Up 2 hours (unhealthy)"}
+{"Name":"app-worker-1","Service":"worker","Image":"app:latest","State":"restarting","Status":"Restarting (1) 3 seconds ago"}
+`
+
+func TestWithPSFormat(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"docker compose ps", "docker compose ps --format json"},
+		{"docker compose -f a.yml ps web", "docker compose -f a.yml ps web --format json"},
+		{"docker compose ps --format table", "docker compose ps --format table"},
+		{"docker compose logs -n 100", "docker compose logs -n 100"},
+	}
+
+	for _, tt := range tests {
+		if got := withPSFormat(tt.command); got != tt.want {
+			t.Errorf("withPSFormat(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestParsePSOutput(t *testing.T) {
+	for name, output := range map[string]string{"array": psArrayOutput, "lines": psLinesOutput} {
+		t.Run(name, func(t *testing.T) {
+			services, err := parsePSOutput(output)
+			if err != nil {
+				t.Fatalf("parsePSOutput() error = %v", err)
+			}
+			if len(services) != 2 || services[0].Service != "web" || services[1].Image != "app:latest" {
+				t.Errorf("services = %+v", services)
+			}
+		})
+	}
+
+	if services, err := parsePSOutput("  \n"); err != nil || len(services) != 0 {
+		t.Errorf("empty output should mean no containers, got %+v, %v", services, err)
+	}
+	if _, err := parsePSOutput("NAME  IMAGE  STATUS"); err == nil {
+		t.Error("expected an error for table output")
+	}
+}
+
+func TestPSServiceText(t *testing.T) {
+	services, _ := parsePSOutput(psArrayOutput)
+
+	web := services[0].text()
+	if web != ":large_green_circle: *web*  `nginx:1.27`\nUp 2 hours · Ports: 8080→80/tcp" {
+		t.Errorf("web text = %q", web)
+	}
+	if worker := services[1].text(); !strings.HasPrefix(worker, ":red_circle: *worker*") || strings.Contains(worker, "Ports") {
+		t.Errorf("worker text = %q", worker)
+	}
+
+	services, _ = parsePSOutput(psLinesOutput)
+	if got := services[0].stateEmoji(); got != ":warning:" {
+		t.Errorf("unhealthy emoji = %q", got)
+	}
+	if got := services[1].stateEmoji(); got != ":arrows_counterclockwise:" {
+		t.Errorf("restarting emoji = %q", got)
+	}
+}
+
+func handlePSOutput(t *testing.T, command, output string) SlackLinerPayload {
+	t.Helper()
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	data, _ := json.Marshal(PoppitCommandOutput{
+		Type:     "slack-compose",
+		Command:  command,
+		Output:   output,
+		Metadata: map[string]interface{}{"project": "my-project"},
+	})
+	svc.handlePoppitOutput(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(rc.pushed))
+	}
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+	return slp
+}
+
+func TestHandlePoppitOutput_PSBlocks(t *testing.T) {
+	slp := handlePSOutput(t, "docker compose ps --format json", psArrayOutput)
+
+	if slp.Blocks == nil {
+		t.Fatal("expected ps output to be sent as blocks")
+	}
+	blocks, _ := json.Marshal(slp.Blocks)
+	if !strings.Contains(string(blocks), "*web*") || !strings.Contains(string(blocks), "8080→80/tcp") {
+		t.Errorf("blocks = %s", blocks)
+	}
+	if strings.Contains(slp.Text, "```") {
+		t.Errorf("fallback text should not include the raw output, got %q", slp.Text)
+	}
+}
+
+func TestHandlePoppitOutput_PSFallsBackToCodeBlock(t *testing.T) {
+	slp := handlePSOutput(t, "docker compose ps --format json", "not json")
+
+	if slp.Blocks != nil {
+		t.Errorf("expected no blocks, got %v", slp.Blocks)
+	}
+	if !strings.Contains(slp.Text, "```\nnot json\n```") {
+		t.Errorf("text = %q", slp.Text)
+	}
+}
+
+func TestPSBlocks_TruncatesStderr(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+	}{
+		{"lines", strings.Repeat("warning: something went wrong\n", 200)},
+		{"one long line", strings.Repeat("a < b & ", 1000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := psBlocks("*ps*", nil, tt.stderr)
+			section, ok := blocks[len(blocks)-1].(*slack.SectionBlock)
+			if !ok {
+				t.Fatalf("last block = %T, want a section", blocks[len(blocks)-1])
+			}
+			text := section.Text.Text
+			if len(text) > maxSectionTextLength {
+				t.Errorf("section text is %d bytes, want at most %d", len(text), maxSectionTextLength)
+			}
+			if !strings.HasSuffix(text, "```"+psStderrTruncated) {
+				t.Errorf("text does not end with the closed code block and notice: %q", text[len(text)-80:])
+			}
+			body := strings.TrimSuffix(text, "\n```"+psStderrTruncated)
+			if i := strings.LastIndexByte(body, '&'); i >= 0 && !strings.Contains(body[i:], ";") {
+				t.Errorf("text ends inside an HTML entity: %q", body[i:])
+			}
+		})
+	}
+
+	short := psBlocks("*ps*", nil, "warning")
+	if text := short[len(short)-1].(*slack.SectionBlock).Text.Text; strings.Contains(text, psStderrTruncated) {
+		t.Errorf("short stderr was truncated: %q", text)
+	}
+}
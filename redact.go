@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redactedMask replaces every secret found in command output
+const redactedMask = "[REDACTED]"
+
+// redactionRule masks one kind of secret. Group is the submatch to mask; 0 masks the whole match.
+type redactionRule struct {
+	name    string
+	pattern *regexp.Regexp
+	group   int
+}
+
+// builtinRedactionRules detect common secrets in logs and compose config output
+var builtinRedactionRules = []redactionRule{
+	{"aws access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), 0},
+	{"slack token", regexp.MustCompile(`\bxox[abposr]-[0-9A-Za-z-]{10,}|\bxapp-[0-9A-Za-z-]{10,}`), 0},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), 0},
+	{"url credentials", regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^:/\s@]+:([^@\s/]+)@`), 1},
+	{"secret assignment", regexp.MustCompile(`(?i)\b[A-Z0-9_.-]*(?:PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|ACCESS_?KEY|PRIVATE_?KEY|CREDENTIALS?)[A-Z0-9_.-]*["']?\s*[=:]\s*["']?([^\s"',;]+)`), 1},
+}
+
+// redactor masks secrets in command output with the built-in rules and a project's own patterns
+type redactor struct {
+	rules []redactionRule
+}
+
+// newRedactor creates a redactor with the built-in rules followed by the custom patterns.
+// A custom pattern with a capture group masks only the first group, otherwise the whole match.
+func newRedactor(patterns []string) (*redactor, error) {
+	rules := append([]redactionRule{}, builtinRedactionRules...)
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		group := 0
+		if re.NumSubexp() > 0 {
+			group = 1
+		}
+		rules = append(rules, redactionRule{name: "custom", pattern: re, group: group})
+	}
+	return &redactor{rules: rules}, nil
+}
+
+// Redact masks every secret in the text and returns the result with the number of values masked
+func (r *redactor) Redact(text string) (string, int) {
+	count := 0
+	for _, rule := range r.rules {
+		var n int
+		text, n = rule.apply(text)
+		count += n
+	}
+	return text, count
+}
+
+// apply masks the rule's matches, skipping values an earlier rule already masked
+func (rule redactionRule) apply(text string) (string, int) {
+	matches := rule.pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text, 0
+	}
+
+	var b strings.Builder
+	count := 0
+	last := 0
+	for _, match := range matches {
+		start, end := match[2*rule.group], match[2*rule.group+1]
+		if start < 0 || start == end || text[start:end] == redactedMask {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(redactedMask)
+		last = end
+		count++
+	}
+	b.WriteString(text[last:])
+	return b.String(), count
+}
+
+// redactionNotice describes how many values were masked in a message
+func redactionNotice(count int) string {
+	if count == 1 {
+		return ":lock: 1 secret was redacted"
+	}
+	return fmt.Sprintf(":lock: %d secrets were redacted", count)
+}
+
+// redactOutput masks secrets in the output and stderr with the project's patterns and returns the number masked
+func (s *Service) redactOutput(projectName string, cmdOutput *PoppitCommandOutput) int {
+	project, _ := s.config.Project(projectName)
+	r, err := newRedactor(project.Redact)
+	if err != nil {
+		// Patterns are validated when the config is loaded; fall back to the built-in rules regardless
+		slog.Error("Invalid redaction pattern, using built-in rules only", "project", projectName, "error", err)
+		r, _ = newRedactor(nil)
+	}
+
+	var outputCount, stderrCount int
+	cmdOutput.Output, outputCount = r.Redact(cmdOutput.Output)
+	cmdOutput.Stderr, stderrCount = r.Redact(cmdOutput.Stderr)
+	return outputCount + stderrCount
+}
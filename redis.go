@@ -2,21 +2,34 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 // PubSubInterface abstracts a Redis pub/sub connection for testability
 type PubSubInterface interface {
+	Receive(ctx context.Context) (interface{}, error)
 	Channel(opts ...redis.ChannelOption) <-chan *redis.Message
 	Close() error
 }
 
 // RedisClientInterface defines the Redis operations used by the Service
 type RedisClientInterface interface {
+	Ping(ctx context.Context) error
 	Subscribe(ctx context.Context, channel string) PubSubInterface
 	RPush(ctx context.Context, key string, value interface{}) error
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	XAdd(ctx context.Context, stream string, values map[string]interface{}) error
+
+	// Consumer group operations used by the streams transport
+	XGroupCreateMkStream(ctx context.Context, stream, group string) error
+	XReadGroup(ctx context.Context, stream, group, consumer, id string, count int64, block time.Duration) ([]redis.XMessage, error)
+	XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, start string, count int64) ([]redis.XMessage, string, error)
+	XAck(ctx context.Context, stream, group string, ids ...string) error
 }
 
 // RedisClient wraps the Redis client
@@ -41,6 +54,11 @@ func NewRedisClient(config *Config) (*RedisClient, error) {
 	return &RedisClient{client: client}, nil
 }
 
+// Ping checks that Redis responds
+func (r *RedisClient) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
 // Subscribe subscribes to a Redis channel
 func (r *RedisClient) Subscribe(ctx context.Context, channel string) PubSubInterface {
 	return r.client.Subscribe(ctx, channel)
@@ -56,6 +74,71 @@ func (r *RedisClient) RPush(ctx context.Context, key string, value interface{})
 	return r.client.RPush(ctx, key, value).Err()
 }
 
+// Expire sets a time-to-live on a key
+func (r *RedisClient) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return r.client.Expire(ctx, key, ttl).Err()
+}
+
+// XAdd appends an entry to a Redis stream
+func (r *RedisClient) XAdd(ctx context.Context, stream string, values map[string]interface{}) error {
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		Values: values,
+	}).Err()
+}
+
+// XGroupCreateMkStream creates a consumer group (and its stream) reading only new entries.
+// An already existing group is not an error.
+func (r *RedisClient) XGroupCreateMkStream(ctx context.Context, stream, group string) error {
+	err := r.client.XGroupCreateMkStream(ctx, stream, group, "$").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// XReadGroup reads entries from a stream as a member of a consumer group.
+// id is ">" for new entries or "0" for entries delivered to this consumer but not yet acknowledged.
+// A read that times out without entries returns no messages and no error.
+func (r *RedisClient) XReadGroup(ctx context.Context, stream, group, consumer, id string, count int64, block time.Duration) ([]redis.XMessage, error) {
+	streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{stream, id},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []redis.XMessage
+	for _, s := range streams {
+		messages = append(messages, s.Messages...)
+	}
+	return messages, nil
+}
+
+// XAutoClaim transfers entries idle for at least minIdle to this consumer, returning the next start ID
+func (r *RedisClient) XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, start string, count int64) ([]redis.XMessage, string, error) {
+	return r.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Start:    start,
+		Count:    count,
+	}).Result()
+}
+
+// XAck acknowledges stream entries for a consumer group
+func (r *RedisClient) XAck(ctx context.Context, stream, group string, ids ...string) error {
+	return r.client.XAck(ctx, stream, group, ids...).Err()
+}
+
 // Close closes the Redis connection
 func (r *RedisClient) Close() error {
 	return r.client.Close()
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// projectChanges summarises the difference between two project configurations
+type projectChanges struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// diffProjects compares the old and new project maps by name
+func diffProjects(oldProjects, newProjects map[string]ProjectConfig) projectChanges {
+	var changes projectChanges
+	for name, project := range newProjects {
+		old, existed := oldProjects[name]
+		switch {
+		case !existed:
+			changes.Added = append(changes.Added, name)
+		case !reflect.DeepEqual(old, project):
+			changes.Changed = append(changes.Changed, name)
+		}
+	}
+	for name := range oldProjects {
+		if _, exists := newProjects[name]; !exists {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Changed)
+	return changes
+}
+
+// Empty reports whether nothing changed
+func (c projectChanges) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// String describes the changes for logs and Slack
+func (c projectChanges) String() string {
+	if c.Empty() {
+		return "no changes"
+	}
+
+	var parts []string
+	for _, part := range []struct {
+		label string
+		names []string
+	}{
+		{"added", c.Added},
+		{"removed", c.Removed},
+		{"changed", c.Changed},
+	} {
+		if len(part.names) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", part.label, strings.Join(part.names, ", ")))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ReloadProjectConfig re-reads the project config file and atomically swaps in the new projects.
+// An unreadable or invalid file keeps the current projects and returns the error.
+func (s *Service) ReloadProjectConfig(ctx context.Context, trigger string) (projectChanges, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	projects, err := readProjectConfig(s.config.ProjectConfigPath)
+	if err != nil {
+		slog.Error("Project config reload failed, keeping current projects", "error", err, "trigger", trigger)
+		if s.config.ProjectReloadNotify {
+			s.sendNotice(ctx, "", "", fmt.Sprintf(":x: Reloading `%s` failed, keeping the current projects: %s",
+				inlineCode(s.config.ProjectConfigPath), escapeMrkdwn(err.Error())))
+		}
+		return projectChanges{}, err
+	}
+
+	changes := diffProjects(s.config.projects(), projects)
+	s.config.setProjects(projects)
+
+	slog.Info("Reloaded project config", "trigger", trigger, "projects", len(projects),
+		"added", changes.Added, "removed", changes.Removed, "changed", changes.Changed)
+	if s.config.ProjectReloadNotify && !changes.Empty() {
+		s.sendNotice(ctx, "", "", fmt.Sprintf(":arrows_counterclockwise: Reloaded `%s` (%s)",
+			inlineCode(s.config.ProjectConfigPath), escapeMrkdwn(changes.String())))
+	}
+	return changes, nil
+}
+
+// watchProjectConfig polls the project config file and reloads it whenever it changes
+func (s *Service) watchProjectConfig(ctx context.Context) {
+	defer s.wg.Done()
+
+	interval := time.Duration(s.config.ProjectConfigWatchSeconds) * time.Second
+	slog.Info("Watching project config for changes", "path", s.config.ProjectConfigPath, "interval", interval)
+
+	last := statProjectConfig(s.config.ProjectConfigPath)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Context cancelled, stopping project config watcher")
+			return
+		case <-ticker.C:
+			current := statProjectConfig(s.config.ProjectConfigPath)
+			if current == last {
+				continue
+			}
+			last = current
+			s.ReloadProjectConfig(ctx, "file change")
+		}
+	}
+}
+
+// fileState identifies a version of a file; the zero value means the file could not be read
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// statProjectConfig returns the current state of the project config file
+func statProjectConfig(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
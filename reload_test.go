@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func writeProjects(t *testing.T, path string, projects []ProjectConfig) {
+	t.Helper()
+	data, _ := json.Marshal(projects)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiffProjects(t *testing.T) {
+	old := map[string]ProjectConfig{
+		"keep":   {Name: "keep", WorkingDir: "/a"},
+		"change": {Name: "change", WorkingDir: "/b"},
+		"remove": {Name: "remove", WorkingDir: "/c"},
+	}
+	updated := map[string]ProjectConfig{
+		"keep":   {Name: "keep", WorkingDir: "/a"},
+		"change": {Name: "change", WorkingDir: "/b", Services: []string{"web"}},
+		"add":    {Name: "add", WorkingDir: "/d"},
+	}
+
+	changes := diffProjects(old, updated)
+	if got := changes.String(); got != "added: add; removed: remove; changed: change" {
+		t.Errorf("changes = %q", got)
+	}
+	if !diffProjects(old, old).Empty() {
+		t.Error("identical configs should have no changes")
+	}
+}
+
+func TestReloadProjectConfig_SwapsProjects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.json")
+	writeProjects(t, path, []ProjectConfig{{Name: "new-project", WorkingDir: "/srv/new"}})
+
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+	svc.config.ProjectConfigPath = path
+	svc.config.ProjectReloadNotify = true
+
+	changes, err := svc.ReloadProjectConfig(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("ReloadProjectConfig() error = %v", err)
+	}
+	if len(changes.Added) != 1 || len(changes.Removed) != 2 {
+		t.Errorf("changes = %+v", changes)
+	}
+	if _, ok := svc.config.Project("new-project"); !ok {
+		t.Error("new-project should be loaded")
+	}
+	if _, ok := svc.config.Project("my-project"); ok {
+		t.Error("my-project should be removed")
+	}
+
+	// The summary is posted to Slack
+	if len(rc.pushed) != 1 || rc.pushed[0].key != "slack_messages" {
+		t.Fatalf("expected 1 summary notice, got %+v", rc.pushed)
+	}
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+	if !strings.Contains(slp.Text, "added: new-project") {
+		t.Errorf("summary = %q", slp.Text)
+	}
+}
+
+func TestReloadProjectConfig_InvalidKeepsProjects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.json")
+	writeProjects(t, path, []ProjectConfig{{Name: "bad", Roles: map[string]Role{"U1": "root"}}})
+
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+	svc.config.ProjectConfigPath = path
+
+	if _, err := svc.ReloadProjectConfig(context.Background(), "test"); err == nil {
+		t.Fatal("expected an error for an invalid role")
+	}
+	if _, ok := svc.config.Project("my-project"); !ok {
+		t.Error("current projects should be kept when the reload fails")
+	}
+	// Notifications are off, so nothing is posted
+	if len(rc.pushed) != 0 {
+		t.Errorf("expected no pushes, got %d", len(rc.pushed))
+	}
+}
+
+func TestReloadProjectConfig_MissingFileKeepsProjects(t *testing.T) {
+	svc := newTestService(nil, nil)
+	svc.config.ProjectConfigPath = filepath.Join(t.TempDir(), "missing.json")
+
+	if _, err := svc.ReloadProjectConfig(context.Background(), "test"); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+	if _, ok := svc.config.Project("my-project"); !ok {
+		t.Error("current projects should be kept when the file is missing")
+	}
+}
+
+func TestReloadProjectConfig_ConcurrentReads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.json")
+	writeProjects(t, path, []ProjectConfig{{Name: "my-project", WorkingDir: "/srv/my-project"}})
+
+	svc := newTestService(nil, nil)
+	svc.config.ProjectConfigPath = path
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				svc.config.Project("my-project")
+				svc.projectOptions("", "")
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		svc.ReloadProjectConfig(context.Background(), "test")
+	}
+	wg.Wait()
+}
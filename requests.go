@@ -0,0 +1,74 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// RequestTrackingTTL is how long a dispatched command waits for its output before it is forgotten
+const RequestTrackingTTL = time.Hour
+
+// trackedRequest is a dispatched command waiting for its output from Poppit
+type trackedRequest struct {
+	ID      string
+	Request commandRequest
+	SentAt  time.Time
+}
+
+// requestTracker matches Poppit output back to the commands that produced it
+type requestTracker struct {
+	mu       sync.Mutex
+	requests map[string]trackedRequest
+}
+
+// add remembers a dispatched command, forgetting commands whose output never arrived
+func (t *requestTracker) add(tracked trackedRequest) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.requests == nil {
+		t.requests = make(map[string]trackedRequest)
+	}
+	for id, r := range t.requests {
+		if tracked.SentAt.Sub(r.SentAt) > RequestTrackingTTL {
+			delete(t.requests, id)
+		}
+	}
+	t.requests[tracked.ID] = tracked
+}
+
+// complete returns and forgets the command with the given request ID
+func (t *requestTracker) complete(id string) (trackedRequest, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked, ok := t.requests[id]
+	if ok {
+		delete(t.requests, id)
+	}
+	return tracked, ok
+}
+
+// count returns the number of commands still waiting for their output
+func (t *requestTracker) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.requests)
+}
+
+// newRequestID returns a unique identifier correlating a command with its output
+func newRequestID() (string, error) {
+	return randomID(16)
+}
+
+// randomID returns n random bytes encoded as hex
+func randomID(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
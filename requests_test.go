@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRequestTracker_CompleteForgetsRequest(t *testing.T) {
+	var tracker requestTracker
+	tracker.add(trackedRequest{ID: "r1", SentAt: time.Now()})
+
+	if _, ok := tracker.complete("r1"); !ok {
+		t.Fatal("expected r1 to be tracked")
+	}
+	if _, ok := tracker.complete("r1"); ok {
+		t.Error("r1 should be forgotten once completed")
+	}
+	if _, ok := tracker.complete(""); ok {
+		t.Error("empty request ID should never match")
+	}
+}
+
+func TestRequestTracker_ForgetsStaleRequests(t *testing.T) {
+	var tracker requestTracker
+	now := time.Now()
+	tracker.add(trackedRequest{ID: "old", SentAt: now.Add(-2 * RequestTrackingTTL)})
+	tracker.add(trackedRequest{ID: "new", SentAt: now})
+
+	if _, ok := tracker.complete("old"); ok {
+		t.Error("stale request should have been forgotten")
+	}
+	if _, ok := tracker.complete("new"); !ok {
+		t.Error("recent request should still be tracked")
+	}
+}
+
+func TestRequestID_RoundTrip(t *testing.T) {
+	sink := &recordingAuditSink{}
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+	svc.audit = sink
+
+	payload := SlackCommand{Command: "/slack-compose", Text: "my-project", UserID: "U1", ChannelID: "C123"}
+	data, _ := json.Marshal(payload)
+	svc.handleCommand(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(rc.pushed))
+	}
+	var pp PoppitPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &pp)
+	requestID, ok := pp.Metadata["request_id"].(string)
+	if !ok || len(requestID) != 32 {
+		t.Fatalf("request_id = %v, want 32 hex characters", pp.Metadata["request_id"])
+	}
+
+	// Poppit echoes the metadata back with the output
+	out := PoppitCommandOutput{
+		Type:     "slack-compose",
+		Command:  pp.Commands[0],
+		Output:   "container1 Up",
+		Metadata: pp.Metadata,
+	}
+	data, _ = json.Marshal(out)
+	svc.handlePoppitOutput(context.Background(), string(data))
+
+	if len(rc.pushed) != 2 {
+		t.Fatalf("expected 2 pushes, got %d", len(rc.pushed))
+	}
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[1].value.([]byte), &slp)
+	if slp.Metadata.EventPayload["request_id"] != requestID {
+		t.Errorf("SlackLiner request_id = %v, want %q", slp.Metadata.EventPayload["request_id"], requestID)
+	}
+
+	if len(sink.records) != 2 {
+		t.Fatalf("expected 2 audit records, got %d", len(sink.records))
+	}
+	outcome := sink.records[1]
+	if outcome.RequestID != requestID || outcome.UserID != "U1" || outcome.Channel != "C123" || outcome.Source != SourceCommand {
+		t.Errorf("outcome record = %+v, want details of the original request", outcome)
+	}
+	if _, ok := svc.requests.complete(requestID); ok {
+		t.Error("request should no longer be tracked after its output arrived")
+	}
+}
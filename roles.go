@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is an access level granted to a Slack user
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+)
+
+// roleRank orders roles from least to most privileged
+var roleRank = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// verbRoles maps docker compose subcommands to the minimum role allowed to run them
+var verbRoles = map[string]Role{
+	"ps":      RoleViewer,
+	"logs":    RoleViewer,
+	"up":      RoleOperator,
+	"restart": RoleOperator,
+	"down":    RoleAdmin,
+}
+
+// Valid reports whether the role is one of the known roles
+func (r Role) Valid() bool {
+	_, ok := roleRank[r]
+	return ok
+}
+
+// Allows reports whether the role is at least as privileged as the required role
+func (r Role) Allows(required Role) bool {
+	return roleRank[r] >= roleRank[required]
+}
+
+// parseRole parses a role name
+func parseRole(value string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(value)))
+	if !role.Valid() {
+		return "", fmt.Errorf("unknown role %q (expected viewer, operator or admin)", value)
+	}
+	return role, nil
+}
+
+// parseUserRoles parses a comma-separated list of user:role assignments (e.g. "U123:admin,U456:viewer")
+func parseUserRoles(value string) (map[string]Role, error) {
+	roles := make(map[string]Role)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		userID, roleName, ok := strings.Cut(entry, ":")
+		if !ok || strings.TrimSpace(userID) == "" {
+			return nil, fmt.Errorf("invalid role assignment %q (expected USER_ID:role)", entry)
+		}
+
+		role, err := parseRole(roleName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid role assignment %q: %w", entry, err)
+		}
+		roles[strings.TrimSpace(userID)] = role
+	}
+	return roles, nil
+}
+
+// composeGlobalOptionsWithValue are the docker compose options placed before the subcommand that take a value
+var composeGlobalOptionsWithValue = map[string]bool{
+	"-f": true, "--file": true,
+	"-p": true, "--project-name": true,
+	"--profile": true, "--env-file": true,
+	"--project-directory": true, "--ansi": true, "--progress": true, "--parallel": true,
+}
+
+// composeVerb returns the docker compose subcommand of a command (e.g. "up" for "docker compose up -d"),
+// skipping any global options such as "-f docker-compose.prod.yml" before it
+func composeVerb(command string) string {
+	fields := strings.Fields(command)
+	for i, field := range fields {
+		if field != "compose" {
+			continue
+		}
+		for j := i + 1; j < len(fields); j++ {
+			switch {
+			case composeGlobalOptionsWithValue[fields[j]]:
+				j++
+			case strings.HasPrefix(fields[j], "-"):
+			default:
+				return fields[j]
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// requiredRole returns the minimum role needed to run a command; unknown commands require admin
+func requiredRole(command string) Role {
+	if role, ok := verbRoles[composeVerb(command)]; ok {
+		return role
+	}
+	return RoleAdmin
+}
+
+// RoleFor returns the role of a Slack user for a project.
+// Project role assignments take precedence over global ones; users without an
+// assignment get the default role. Without DEFAULT_ROLE that is viewer once any
+// project assigns roles, and admin only when access control is not configured at all.
+func (c *Config) RoleFor(userID string, project ProjectConfig) Role {
+	if role, ok := project.Roles[userID]; ok {
+		return role
+	}
+	if role, ok := c.UserRoles[userID]; ok {
+		return role
+	}
+	if c.DefaultRole != "" {
+		return c.DefaultRole
+	}
+	if len(project.Roles) > 0 || c.projectRolesAssigned() {
+		return RoleViewer
+	}
+	return RoleAdmin
+}
+
+// projectRolesAssigned reports whether any project assigns roles
+func (c *Config) projectRolesAssigned() bool {
+	for _, project := range c.projects() {
+		if len(project.Roles) > 0 {
+			return true
+		}
+	}
+	return false
+}
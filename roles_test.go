@@ -0,0 +1,92 @@
+package main
+
+import "testing"
+
+func TestParseUserRoles(t *testing.T) {
+	roles, err := parseUserRoles("U1:admin, U2:Operator,U3:viewer,")
+	if err != nil {
+		t.Fatalf("parseUserRoles() error = %v", err)
+	}
+	want := map[string]Role{"U1": RoleAdmin, "U2": RoleOperator, "U3": RoleViewer}
+	if len(roles) != len(want) {
+		t.Fatalf("got %d roles, want %d", len(roles), len(want))
+	}
+	for user, role := range want {
+		if roles[user] != role {
+			t.Errorf("role for %s = %q, want %q", user, roles[user], role)
+		}
+	}
+}
+
+func TestParseUserRoles_Invalid(t *testing.T) {
+	for _, value := range []string{"U1", "U1:superuser", ":admin"} {
+		if _, err := parseUserRoles(value); err == nil {
+			t.Errorf("parseUserRoles(%q) should return error", value)
+		}
+	}
+}
+
+func TestRequiredRole(t *testing.T) {
+	tests := []struct {
+		command string
+		want    Role
+	}{
+		{"docker compose ps", RoleViewer},
+		{"docker compose logs -n 100", RoleViewer},
+		{"docker compose up -d", RoleOperator},
+		{"docker compose restart", RoleOperator},
+		{"docker compose down", RoleAdmin},
+		{"docker compose rm -f", RoleAdmin},
+		{"docker compose -f a.yml --file=b.yml -p stack --profile monitoring --env-file .env.prod up -d", RoleOperator},
+		{"docker compose --dry-run logs", RoleViewer},
+		{"", RoleAdmin},
+	}
+
+	for _, tt := range tests {
+		if got := requiredRole(tt.command); got != tt.want {
+			t.Errorf("requiredRole(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestRole_Allows(t *testing.T) {
+	if !RoleAdmin.Allows(RoleOperator) {
+		t.Error("admin should be allowed operator actions")
+	}
+	if RoleViewer.Allows(RoleOperator) {
+		t.Error("viewer should not be allowed operator actions")
+	}
+	if Role("").Allows(RoleViewer) {
+		t.Error("empty role should not be allowed anything")
+	}
+}
+
+func TestConfig_RoleFor(t *testing.T) {
+	cfg := &Config{
+		UserRoles:   map[string]Role{"U1": RoleOperator},
+		DefaultRole: RoleViewer,
+	}
+	project := ProjectConfig{Name: "prod", Roles: map[string]Role{"U1": RoleAdmin}}
+
+	if got := cfg.RoleFor("U1", project); got != RoleAdmin {
+		t.Errorf("project role should take precedence, got %q", got)
+	}
+	if got := cfg.RoleFor("U1", ProjectConfig{Name: "other"}); got != RoleOperator {
+		t.Errorf("global role = %q, want %q", got, RoleOperator)
+	}
+	if got := cfg.RoleFor("U2", project); got != RoleViewer {
+		t.Errorf("default role = %q, want %q", got, RoleViewer)
+	}
+	if got := (&Config{}).RoleFor("U2", ProjectConfig{Name: "other"}); got != RoleAdmin {
+		t.Errorf("unconfigured access control should grant admin, got %q", got)
+	}
+
+	// Roles assigned only in projects.json still restrict everyone else
+	projectOnly := &Config{Projects: map[string]ProjectConfig{"prod": project}}
+	if got := projectOnly.RoleFor("U2", project); got != RoleViewer {
+		t.Errorf("unlisted user on a project with roles = %q, want %q", got, RoleViewer)
+	}
+	if got := projectOnly.RoleFor("U2", ProjectConfig{Name: "other"}); got != RoleViewer {
+		t.Errorf("unlisted user while another project assigns roles = %q, want %q", got, RoleViewer)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// zeroWidthSpace is inserted into sequences that would otherwise be read as mrkdwn syntax
+const zeroWidthSpace = "\u200b"
+
+// terminalSequencePattern matches ANSI escape sequences: CSI (colours, cursor movement),
+// OSC (window titles, hyperlinks) and two-character escapes
+var terminalSequencePattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// mrkdwnEscaper escapes the characters Slack treats as control sequences in mrkdwn
+var mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// stripTerminalControls removes ANSI escape sequences and other control characters from
+// command output. Carriage returns are resolved the way a terminal shows them: only the
+// text after the last one on a line is kept.
+func stripTerminalControls(text string) string {
+	text = terminalSequencePattern.ReplaceAllString(text, "")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	if strings.Contains(text, "\r") {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			segments := strings.Split(strings.TrimRight(line, "\r"), "\r")
+			lines[i] = segments[len(segments)-1]
+		}
+		text = strings.Join(lines, "\n")
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			return -1
+		default:
+			return r
+		}
+	}, text)
+}
+
+// escapeMrkdwn escapes text interpolated into a Slack mrkdwn message
+func escapeMrkdwn(text string) string {
+	return mrkdwnEscaper.Replace(text)
+}
+
+// codeBlockText prepares command output for a fenced code block: mrkdwn is escaped and
+// runs of backticks are broken up so the output cannot close the block early
+func codeBlockText(text string) string {
+	text = escapeMrkdwn(text)
+	for strings.Contains(text, codeFence) {
+		text = strings.ReplaceAll(text, codeFence, "``"+zeroWidthSpace+"`")
+	}
+	return text
+}
+
+// inlineCode prepares a value such as a command for inline code: mrkdwn is escaped and
+// backticks, which would end the code span, are replaced with quotes
+func inlineCode(text string) string {
+	return strings.ReplaceAll(escapeMrkdwn(stripTerminalControls(text)), "`", "'")
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStripTerminalControls(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"colours", "\x1b[32mweb-1\x1b[0m  | \x1b[1;31mERROR\x1b[m ready", "web-1  | ERROR ready"},
+		{"cursor movement", "\x1b[2K\x1b[1A\x1b[1Gpulling", "pulling"},
+		{"hyperlink", "\x1b]8;;http://evil.example\x07click\x1b]8;;\x07", "click"},
+		{"window title", "\x1b]0;title\x1b\\done", "done"},
+		{"carriage return progress", "10%\r50%\r100%\nnext", "100%\nnext"},
+		{"crlf", "a\r\nb\r\n", "a\nb\n"},
+		{"other controls", "bell\x07 back\x08space\x00 tab\tkept", "bell backspace tab\tkept"},
+		{"unicode kept", "✔ Container web Started", "✔ Container web Started"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripTerminalControls(tt.input); got != tt.want {
+				t.Errorf("stripTerminalControls(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeBlockText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a < b && c > d", "a &lt; b &amp;&amp; c &gt; d"},
+		{"<!channel> <@U123> <http://x|y>", "&lt;!channel&gt; &lt;@U123&gt; &lt;http://x|y&gt;"},
+		{"&amp; stays literal", "&amp;amp; stays literal"},
+		{"```\n*bold* outside```", "``\u200b`\n*bold* outside``\u200b`"},
+		{"`````", "``\u200b``\u200b`"},
+	}
+
+	for _, tt := range tests {
+		got := codeBlockText(tt.input)
+		if got != tt.want {
+			t.Errorf("codeBlockText(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+		if strings.Contains(got, codeFence) {
+			t.Errorf("codeBlockText(%q) still contains a fence", tt.input)
+		}
+	}
+}
+
+func TestInlineCode(t *testing.T) {
+	if got := inlineCode("echo `id` > /tmp/x\x1b[0m"); got != "echo 'id' &gt; /tmp/x" {
+		t.Errorf("inlineCode() = %q", got)
+	}
+}
+
+func TestHandlePoppitOutput_HostileOutput(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	data, _ := json.Marshal(PoppitCommandOutput{
+		Type:     "slack-compose",
+		Command:  "docker compose logs `whoami` <@U999>",
+		Output:   "\x1b[31mweb-1 | ```\x1b[0m\n*not bold* <!here>\n```",
+		Stderr:   "<https://evil.example|click me>",
+		Metadata: map[string]interface{}{"project": "my-project"},
+	})
+	svc.handlePoppitOutput(context.Background(), string(data))
+
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+
+	want := "*Project:* my-project\n*Command:* `docker compose logs 'whoami' &lt;@U999&gt;`\n" +
+		"```\nweb-1 | ``\u200b`\n*not bold* &lt;!here&gt;\n``\u200b`\n```\n" +
+		"*Standard Error:*\n```\n&lt;https://evil.example|click me&gt;\n```"
+	if slp.Text != want {
+		t.Errorf("text = %q\nwant   %q", slp.Text, want)
+	}
+}
+
+func TestPSServiceText_Escaped(t *testing.T) {
+	service := psService{Service: "web<1>", Image: "img`x", State: "running", Status: "Up <1s"}
+	if got := service.text(); got != ":large_green_circle: *web&lt;1&gt;*  `img'x`\nUp &lt;1s" {
+		t.Errorf("text = %q", got)
+	}
+}
+
+func TestNotices_Escaped(t *testing.T) {
+	hostile := ProjectConfig{Name: "app<!channel>", AllowedChannels: []string{"CPROD"}, Roles: map[string]Role{"U1": RoleViewer}}
+	req := commandRequest{
+		ID:            "req-1",
+		Project:       hostile,
+		Action:        Action{Name: "down", Command: "docker compose down", Role: RoleAdmin},
+		Command:       "docker compose down `id` <!here>",
+		UserID:        "U1",
+		SourceChannel: "C123",
+	}
+
+	tests := []struct {
+		name string
+		send func(svc *Service)
+		want string
+	}{
+		{
+			"confirmation prompt",
+			func(svc *Service) { svc.requestConfirmation(context.Background(), req) },
+			"requested `docker compose down 'id' &lt;!here&gt;` on project *app&lt;!channel&gt;*",
+		},
+		{
+			"role denied",
+			func(svc *Service) { svc.authorize(context.Background(), req) },
+			"on project *app&lt;!channel&gt;* does not allow `docker compose down 'id' &lt;!here&gt;`",
+		},
+		{
+			"channel not allowed",
+			func(svc *Service) {
+				svc.sendNotice(context.Background(), "C123", "", channelNotAllowedMessage(hostile))
+			},
+			"Project *app&lt;!channel&gt;* cannot be controlled",
+		},
+		{
+			"unknown service",
+			func(svc *Service) {
+				r := req
+				r.Project = ProjectConfig{Name: "my-project", Services: []string{"web"}}
+				r.Services = []string{"<!here>"}
+				svc.checkServices(context.Background(), r)
+			},
+			"&lt;!here&gt;",
+		},
+		{
+			"reload failed",
+			func(svc *Service) {
+				path := filepath.Join(t.TempDir(), "projects.json")
+				writeProjects(t, path, []ProjectConfig{{Name: "bad", Roles: map[string]Role{"U1": "<!here>"}}})
+				svc.config.ProjectConfigPath = path
+				svc.config.ProjectReloadNotify = true
+				svc.ReloadProjectConfig(context.Background(), "test")
+			},
+			"unknown role \"&lt;!here&gt;\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &mockRedisClient{}
+			svc := newTestService(rc, nil)
+			tt.send(svc)
+
+			if len(rc.pushed) != 1 {
+				t.Fatalf("expected 1 push, got %d", len(rc.pushed))
+			}
+			var slp SlackLinerPayload
+			json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+			if !strings.Contains(slp.Text, tt.want) {
+				t.Errorf("text = %q, want it to contain %q", slp.Text, tt.want)
+			}
+			if strings.Contains(slp.Text, "<!") {
+				t.Errorf("text contains an unescaped mention: %q", slp.Text)
+			}
+		})
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/slack-go/slack"
 )
@@ -27,93 +30,140 @@ const (
 	// Block Kit element IDs
 	BlockIDProjectBlock  = "project_block"
 	ActionIDSlackCompose = "SlackCompose"
+	BlockIDServicesBlock = "services_block"
+	ActionIDServices     = "services_select"
 
-	// Git branch reference
+	// maxServiceOptions is the most options Slack accepts in a select
+	maxServiceOptions = 100
+
+	// Git branch reference used for projects without a branch
 	DefaultGitBranch = "refs/heads/main"
 
 	// DefaultTTLSeconds is the default time-to-live for SlackLiner messages (24 hours)
 	DefaultTTLSeconds = 86400
-)
 
-// emojiToCommand maps supported emoji reactions to their docker compose commands
-var emojiToCommand = map[string]string{
-	EmojiUpArrow:                "docker compose up -d",
-	EmojiDownArrow:              "docker compose down",
-	EmojiArrowsCounterClockwise: "docker compose restart",
-	EmojiPageFacingUp:           "docker compose logs",
-}
+	// DefaultConfirmationTimeoutSeconds is how long a destructive command waits for confirmation
+	DefaultConfirmationTimeoutSeconds = 60
 
-// actionIDToCommand maps block action IDs to their docker compose commands
-var actionIDToCommand = map[string]string{
-	ActionDockerUp:      "docker compose up -d",
-	ActionDockerDown:    "docker compose down",
-	ActionDockerRestart: "docker compose restart",
-	ActionDockerPS:      "docker compose ps",
-	ActionDockerLogs:    "docker compose logs",
-}
+	// DefaultCommandAction is the action run by /slack-compose <project>
+	DefaultCommandAction = "ps"
+)
 
 // Service is the main service handler
 type Service struct {
 	config      *Config
 	redisClient RedisClientInterface
 	slackClient SlackClientInterface
+	audit       AuditSink
 	wg          sync.WaitGroup
+
+	// Destructive commands awaiting confirmation, keyed by confirmation ID
+	pendingMu sync.Mutex
+	pending   map[string]pendingConfirmation
+
+	// Dispatched commands awaiting their output, keyed by request ID
+	requests requestTracker
+
+	// Serialises project config reloads from the watcher and SIGHUP
+	reloadMu sync.Mutex
+
+	// Listener subscriptions and message counts reported by the health endpoints
+	listeners listenerStats
+	startedAt time.Time
+
+	// Prometheus collectors served on /metrics; nil records nothing
+	metrics *metrics
+
+	// Events are handled on the worker pool, in order per project; nil handles them inline.
+	// listening tracks the listeners submitting to it.
+	workers   *workerPool
+	listening sync.WaitGroup
+
+	// Handlers run with work, which outlives the listeners' context until abort is called
+	// at the shutdown deadline; inFlight counts the handlers running
+	work     context.Context
+	abort    context.CancelFunc
+	inFlight atomic.Int64
 }
 
 // NewService creates a new service instance
-func NewService(config *Config, redisClient RedisClientInterface) *Service {
-	return &Service{
+func NewService(config *Config, redisClient RedisClientInterface, audit AuditSink) *Service {
+	s := &Service{
 		config:      config,
 		redisClient: redisClient,
 		slackClient: NewSlackClient(config.SlackToken),
+		audit:       audit,
+		metrics:     newMetrics(),
+		workers:     newWorkerPool(config.WorkerPoolSize, config.WorkerQueueSize, config.WorkerQueueFullPolicy),
 	}
+	s.metrics.observeWorkers(s.workers)
+	return s
 }
 
-// getCommandForEmoji returns the docker compose command for a given emoji reaction
-func (s *Service) getCommandForEmoji(emoji string) (string, bool) {
-	baseCmd, ok := emojiToCommand[emoji]
+// getCommandForEmoji returns the catalog action and expanded command for a given emoji reaction
+func (s *Service) getCommandForEmoji(emoji string) (Action, string, bool) {
+	action, ok := s.config.ActionByEmoji(emoji)
 	if !ok {
-		return "", false
+		return Action{}, "", false
 	}
-	return s.expandCommand(baseCmd), true
+	return action, s.expandCommand(action.Command), true
 }
 
-// getCommandForActionID returns the docker compose command for a given action ID
-func (s *Service) getCommandForActionID(actionID string) (string, bool) {
-	baseCmd, ok := actionIDToCommand[actionID]
+// getCommandForActionID returns the catalog action and expanded command for a given action ID
+func (s *Service) getCommandForActionID(actionID string) (Action, string, bool) {
+	action, ok := s.config.ActionByID(actionID)
 	if !ok {
-		return "", false
+		return Action{}, "", false
 	}
-	return s.expandCommand(baseCmd), true
+	return action, s.expandCommand(action.Command), true
 }
 
-// expandCommand expands docker compose commands with config values
-func (s *Service) expandCommand(cmd string) string {
-	if cmd == "docker compose logs" {
-		return fmt.Sprintf("docker compose logs -n %d", s.config.DockerLogsLineLimit)
-	}
-	return cmd
+// expandCommand fills in the placeholders of an action's command template with config values
+func (s *Service) expandCommand(template string) string {
+	return expandCommandTemplate(template, s.config.DockerLogsLineLimit)
+}
+
+// expandCommandTemplate fills in the placeholders of a command template
+func expandCommandTemplate(template string, logLines int) string {
+	return strings.ReplaceAll(template, CommandPlaceholderLogLines, fmt.Sprintf("%d", logLines))
 }
 
 // Start starts the service
 func (s *Service) Start(ctx context.Context) error {
 	slog.Info("Service starting...")
+	s.startedAt = time.Now().UTC()
+	s.beginWork(ctx)
+
+	// Serve the health endpoints when an address is configured
+	if s.config.HTTPAddr != "" {
+		if err := s.startHTTPServer(ctx); err != nil {
+			return err
+		}
+	}
 
 	// Start listening for Slack commands
-	s.wg.Add(1)
-	go s.listenForCommands(ctx)
+	s.startListener(ctx, s.listenForCommands)
 
 	// Start listening for Slack reactions
-	s.wg.Add(1)
-	go s.listenForReactions(ctx)
+	s.startListener(ctx, s.listenForReactions)
 
 	// Start listening for Poppit command output
-	s.wg.Add(1)
-	go s.listenForPoppitOutput(ctx)
+	s.startListener(ctx, s.listenForPoppitOutput)
 
 	// Start listening for Slack block actions
-	s.wg.Add(1)
-	go s.listenForBlockActions(ctx)
+	s.startListener(ctx, s.listenForBlockActions)
+
+	// Start answering project picker queries
+	s.startListener(ctx, s.listenForSuggestions)
+
+	// Handle the events on the worker pool until every listener has stopped
+	s.startWorkers()
+
+	// Start watching the project config file for changes
+	if s.config.ProjectConfigWatchSeconds > 0 {
+		s.wg.Add(1)
+		go s.watchProjectConfig(ctx)
+	}
 
 	slog.Info("Service started successfully")
 	return nil
@@ -121,25 +171,7 @@ func (s *Service) Start(ctx context.Context) error {
 
 // listenForCommands listens for Slack commands from SlackCommandRelay
 func (s *Service) listenForCommands(ctx context.Context) {
-	defer s.wg.Done()
-
-	pubsub := s.redisClient.Subscribe(ctx, s.config.SlackCommandChannel)
-	defer pubsub.Close()
-
-	slog.Info("Listening for commands", "channel", s.config.SlackCommandChannel)
-
-	ch := pubsub.Channel()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case msg := <-ch:
-			if msg == nil {
-				continue
-			}
-			s.handleCommand(ctx, msg.Payload)
-		}
-	}
+	s.listen(ctx, "commands", s.config.SlackCommandChannel, s.handleCommand, commandEventKey)
 }
 
 // handleCommand processes incoming Slack commands
@@ -147,6 +179,7 @@ func (s *Service) handleCommand(ctx context.Context, payload string) {
 	var cmd SlackCommand
 	if err := json.Unmarshal([]byte(payload), &cmd); err != nil {
 		slog.Error("Failed to parse command", "error", err)
+		s.metrics.eventIgnored(IgnoreReasonParseError)
 		return
 	}
 
@@ -157,66 +190,29 @@ func (s *Service) handleCommand(ctx context.Context, payload string) {
 
 	slog.Info("Received /slack-compose command", "text", cmd.Text)
 
-	// Extract project name from command text
-	projectName := strings.TrimSpace(cmd.Text)
-
-	// Check if project is empty or invalid - display block kit dialog
-	if projectName == "" {
-		slog.Info("No project name provided, showing block kit dialog")
-		s.sendBlockKitDialog(ctx, cmd.ChannelID)
+	parsed, err := parseCommandText(cmd.Text)
+	if err != nil {
+		slog.Info("Invalid /slack-compose command", "text", cmd.Text, "error", err)
+		s.sendUsage(ctx, cmd.ChannelID, err.Error())
 		return
 	}
 
-	// Check if project exists in config
-	project, exists := s.config.Projects[projectName]
-	if !exists {
-		slog.Warn("Unknown project requested, showing block kit dialog", "project", projectName)
+	switch parsed.Kind {
+	case commandDialog:
+		slog.Info("No project name provided, showing block kit dialog")
 		s.sendBlockKitDialog(ctx, cmd.ChannelID)
-		return
-	}
-
-	// Send docker compose ps command to Poppit
-	poppitPayload := PoppitPayload{
-		Repo:     projectName,
-		Branch:   DefaultGitBranch,
-		Type:     "slack-compose",
-		Dir:      project.WorkingDir,
-		Commands: []string{"docker compose ps"},
-		Metadata: map[string]interface{}{
-			"project": projectName,
-		},
-	}
-
-	if err := s.sendToPoppit(ctx, poppitPayload); err != nil {
-		slog.Error("Failed to send to Poppit", "error", err, "project", projectName)
-		return
+	case commandList:
+		s.sendProjectList(ctx, cmd.ChannelID)
+	case commandHelp:
+		s.sendNotice(ctx, cmd.ChannelID, "", s.usage())
+	case commandRun:
+		s.runCommand(ctx, cmd, parsed)
 	}
-
-	slog.Info("Sent docker compose ps command", "project", projectName)
 }
 
 // listenForPoppitOutput listens for command output from Poppit
 func (s *Service) listenForPoppitOutput(ctx context.Context) {
-	defer s.wg.Done()
-
-	pubsub := s.redisClient.Subscribe(ctx, s.config.PoppitOutputChannel)
-	defer pubsub.Close()
-
-	slog.Info("Listening for Poppit output", "channel", s.config.PoppitOutputChannel)
-
-	ch := pubsub.Channel()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case msg := <-ch:
-			if msg == nil {
-				slog.Warn("Received nil message from Poppit output channel, possible connection issue")
-				continue
-			}
-			s.handlePoppitOutput(ctx, msg.Payload)
-		}
-	}
+	s.listen(ctx, "poppit_output", s.config.PoppitOutputChannel, s.handlePoppitOutput, poppitOutputEventKey)
 }
 
 // handlePoppitOutput handles output from Poppit and sends it to SlackLiner
@@ -224,13 +220,7 @@ func (s *Service) handlePoppitOutput(ctx context.Context, payload string) {
 	var cmdOutput PoppitCommandOutput
 	if err := json.Unmarshal([]byte(payload), &cmdOutput); err != nil {
 		slog.Error("Failed to parse Poppit output", "error", err)
-		return
-	}
-
-	slog.Debug("Received output", "command", cmdOutput.Command)
-
-	// Only handle output for slack-compose type
-	if cmdOutput.Type != "slack-compose" {
+		s.metrics.eventIgnored(IgnoreReasonParseError)
 		return
 	}
 
@@ -238,6 +228,7 @@ func (s *Service) handlePoppitOutput(ctx context.Context, payload string) {
 	projectName := ""
 	threadTS := ""
 	channel := ""
+	requestID := ""
 	if cmdOutput.Metadata != nil {
 		if proj, ok := cmdOutput.Metadata["project"].(string); ok {
 			projectName = proj
@@ -248,11 +239,41 @@ func (s *Service) handlePoppitOutput(ctx context.Context, payload string) {
 		if ch, ok := cmdOutput.Metadata["channel"].(string); ok {
 			channel = ch
 		}
+		if id, ok := cmdOutput.Metadata["request_id"].(string); ok {
+			requestID = id
+		}
+	}
+
+	log := slog.With("request_id", requestID)
+	log.Debug("Received output", "command", cmdOutput.Command)
+
+	// Only handle output for slack-compose type
+	if cmdOutput.Type != "slack-compose" {
+		return
 	}
 
 	if projectName == "" {
-		slog.Warn("No project name in metadata")
+		log.Warn("No project name in metadata")
 	}
+	services := metadataStrings(cmdOutput.Metadata, "services")
+
+	// Match the output back to the request that produced it
+	var matched *trackedRequest
+	if tracked, ok := s.requests.complete(requestID); ok {
+		matched = &tracked
+		s.metrics.roundTrip(tracked.Request.Project.Name, tracked.Request.Action.Name, time.Since(tracked.SentAt))
+		log.Info("Matched output to request",
+			"project", projectName,
+			"user", tracked.Request.UserID,
+			"source", tracked.Request.Source,
+			"thread_ts", tracked.Request.ThreadTS,
+			"sent_at", tracked.SentAt,
+			"latency", time.Since(tracked.SentAt))
+	} else if requestID != "" {
+		log.Warn("Output for unknown request", "project", projectName)
+	}
+
+	s.recordAudit(ctx, outcomeAuditRecord(cmdOutput, projectName, channel, requestID, matched))
 
 	// Build metadata for SlackLiner
 	eventPayload := map[string]interface{}{
@@ -261,6 +282,13 @@ func (s *Service) handlePoppitOutput(ctx context.Context, payload string) {
 	if projectName != "" {
 		eventPayload["project"] = projectName
 	}
+	if requestID != "" {
+		eventPayload["request_id"] = requestID
+	}
+	// Reactions on the output act on the same services
+	if len(services) > 0 {
+		eventPayload["services"] = services
+	}
 
 	// Use the channel from metadata if available, otherwise use default
 	targetChannel := s.config.SlackChannel
@@ -268,133 +296,319 @@ func (s *Service) handlePoppitOutput(ctx context.Context, payload string) {
 		targetChannel = channel
 	}
 
-	// Build the message text with output and/or stderr
-	messageText := fmt.Sprintf("*Project:* %s\n*Command:* `%s`", projectName, cmdOutput.Command)
+	// Terminal escapes are stripped and secrets masked before anything is posted to Slack
+	cmdOutput.Output = stripTerminalControls(cmdOutput.Output)
+	cmdOutput.Stderr = stripTerminalControls(cmdOutput.Stderr)
+	redacted := s.redactOutput(projectName, &cmdOutput)
+	if redacted > 0 {
+		log.Info("Redacted secrets from output", "project", projectName, "count", redacted)
+	}
 
-	// Only show output if non-empty
-	if cmdOutput.Output != "" {
-		messageText += fmt.Sprintf("\n```\n%s\n```", cmdOutput.Output)
+	header := fmt.Sprintf("*Project:* %s\n*Command:* `%s`", escapeMrkdwn(projectName), inlineCode(cmdOutput.Command))
+	if redacted > 0 {
+		header += "\n" + redactionNotice(redacted)
+	}
+	newPayload := func(text string) SlackLinerPayload {
+		return SlackLinerPayload{
+			Channel: targetChannel,
+			Text:    text,
+			Metadata: SlackMetadata{
+				EventType:    "slack-compose",
+				EventPayload: eventPayload,
+			},
+			TTL:      DefaultTTLSeconds,
+			ThreadTS: threadTS,
+		}
 	}
 
-	// Only show stderr if non-empty
-	if cmdOutput.Stderr != "" {
-		messageText += fmt.Sprintf("\n*Standard Error:*\n```\n%s\n```", cmdOutput.Stderr)
+	var payloads []SlackLinerPayload
+
+	// ps output is shown per service; output that does not parse falls back to the code block
+	if isPSJSON(cmdOutput.Command) {
+		if psServices, err := parsePSOutput(cmdOutput.Output); err != nil {
+			log.Warn("Failed to parse ps output, sending it as text", "error", err)
+		} else {
+			payload := newPayload(header)
+			payload.Blocks = psBlocks(header, psServices, cmdOutput.Stderr)
+			payloads = append(payloads, payload)
+		}
 	}
 
-	slackLinerPayload := SlackLinerPayload{
-		Channel: targetChannel,
-		Text:    messageText,
-		Metadata: SlackMetadata{
-			EventType:    "slack-compose",
-			EventPayload: eventPayload,
-		},
-		TTL:      DefaultTTLSeconds,
-		ThreadTS: threadTS,
+	// Output and stderr are only shown if non-empty, split over as many messages as needed
+	if len(payloads) == 0 {
+		outputPart := outputSection{Body: cmdOutput.Output}
+
+		// Large output is attached as a file with only a preview posted inline
+		if s.shouldUpload(cmdOutput.Output) {
+			if preview, err := s.uploadOutput(ctx, targetChannel, threadTS, projectName, cmdOutput.Command, cmdOutput.Output); err != nil {
+				log.Warn("Failed to upload output, posting it inline", "error", err)
+			} else {
+				outputPart = preview
+			}
+		}
+
+		sections := []outputSection{
+			outputPart,
+			{Title: "*Standard Error:*", Body: cmdOutput.Stderr},
+		}
+		for i := range sections {
+			sections[i].Body = codeBlockText(sections[i].Body)
+		}
+		for _, chunk := range chunkOutput(header, sections, s.config.OutputChunkSize, s.config.OutputMaxChunks) {
+			payloads = append(payloads, newPayload(chunk))
+		}
 	}
 
-	if err := s.sendToSlackLiner(ctx, slackLinerPayload); err != nil {
-		slog.Error("Failed to send to SlackLiner", "error", err)
-		return
+	for _, payload := range payloads {
+		if err := s.sendToSlackLiner(ctx, payload); err != nil {
+			log.Error("Failed to send to SlackLiner", "error", err)
+			return
+		}
 	}
 
-	slog.Info("Sent output to SlackLiner", "project", projectName)
+	log.Info("Sent output to SlackLiner", "project", projectName, "messages", len(payloads))
 }
 
-// listenForReactions listens for emoji reactions from SlackRelay
-func (s *Service) listenForReactions(ctx context.Context) {
-	defer s.wg.Done()
-
-	pubsub := s.redisClient.Subscribe(ctx, s.config.SlackReactionChannel)
-	defer pubsub.Close()
+// outcomeAuditRecord builds the audit record for a command's output,
+// preferring the details of the tracked request over the output's metadata
+func outcomeAuditRecord(cmdOutput PoppitCommandOutput, projectName, channel, requestID string, tracked *trackedRequest) AuditRecord {
+	record := AuditRecord{
+		Event:       AuditEventOutcome,
+		RequestID:   requestID,
+		Channel:     channel,
+		Project:     projectName,
+		Command:     cmdOutput.Command,
+		Outcome:     AuditOutcomeCompleted,
+		OutputBytes: len(cmdOutput.Output),
+		StderrBytes: len(cmdOutput.Stderr),
+	}
 
-	slog.Info("Listening for reactions", "channel", s.config.SlackReactionChannel)
+	if tracked != nil {
+		record.UserID = tracked.Request.UserID
+		record.Channel = tracked.Request.SourceChannel
+		record.Source = tracked.Request.Source
+		record.LatencyMS = time.Since(tracked.SentAt).Milliseconds()
+		return record
+	}
 
-	ch := pubsub.Channel()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case msg := <-ch:
-			if msg == nil {
-				continue
-			}
-			s.handleReaction(ctx, msg.Payload)
-		}
+	if user, ok := cmdOutput.Metadata["user"].(string); ok {
+		record.UserID = user
+	}
+	if source, ok := cmdOutput.Metadata["source"].(string); ok {
+		record.Source = source
 	}
+	return record
+}
+
+// listenForReactions listens for emoji reactions from SlackRelay
+func (s *Service) listenForReactions(ctx context.Context) {
+	s.listenStaged(ctx, "reactions", s.config.SlackReactionChannel, s.routeReaction, reactionEventKey)
 }
 
 // handleReaction processes emoji reactions
 func (s *Service) handleReaction(ctx context.Context, payload string) {
+	if _, next := s.routeReaction(ctx, payload); next != nil {
+		next(ctx)
+	}
+}
+
+// routeReaction finds the project a reaction is about by fetching the reacted message from Slack.
+// It returns the project's worker key and the rest of the handling, or nil if the reaction is ignored.
+func (s *Service) routeReaction(ctx context.Context, payload string) (string, func(context.Context)) {
 	var reaction SlackReaction
 	if err := json.Unmarshal([]byte(payload), &reaction); err != nil {
 		slog.Error("Failed to parse reaction", "error", err)
-		return
+		s.metrics.eventIgnored(IgnoreReasonParseError)
+		return "", nil
 	}
 
 	slog.Debug("Received reaction", "emoji", reaction.Event.Reaction, "message", reaction.Event.Item.TS, "channel", reaction.Event.Item.Channel)
 
+	// Confirmations of pending destructive commands
+	if reaction.Event.Reaction == EmojiWhiteCheckMark {
+		return s.routeConfirmationReaction(ctx, reaction)
+	}
+
 	// Check if this is a supported reaction
 	// Unsupported reactions are logged at DEBUG level to avoid cluttering logs with reactions we don't care about
-	command, supported := s.getCommandForEmoji(reaction.Event.Reaction)
+	action, command, supported := s.getCommandForEmoji(reaction.Event.Reaction)
 	if !supported {
 		slog.Debug("Unsupported reaction, ignoring", "emoji", reaction.Event.Reaction)
-		return
+		s.metrics.eventIgnored(IgnoreReasonUnsupportedEmoji)
+		return "", nil
 	}
 
 	// Retrieve message from Slack to get metadata
 	message, err := s.slackClient.GetMessage(ctx, reaction.Event.Item.Channel, reaction.Event.Item.TS)
 	if err != nil {
 		slog.Error("Failed to retrieve message", "error", err)
-		return
+		s.metrics.slackAPIError(SlackMethodGetMessage)
+		return "", nil
 	}
 
 	// Parse metadata
 	if message.Metadata.EventType != "slack-compose" {
 		slog.Debug("Message is not a slack-compose event, ignoring")
-		return
+		return "", nil
 	}
 
 	projectName, ok := message.Metadata.EventPayload["project"].(string)
 	if !ok || projectName == "" {
 		slog.Warn("No project name in metadata")
-		return
+		return "", nil
 	}
 
+	// Reactions act on the services targeted by the command that produced the message
+	services := metadataStrings(message.Metadata.EventPayload, "services")
+
+	return projectKey(projectName), func(ctx context.Context) {
+		s.runReaction(ctx, reaction, action, command, projectName, services)
+	}
+}
+
+// runReaction submits the command for a reaction on a message about the project
+func (s *Service) runReaction(ctx context.Context, reaction SlackReaction, action Action, command, projectName string, services []string) {
 	// Check if project exists
-	project, exists := s.config.Projects[projectName]
+	project, exists := s.config.Project(projectName)
 	if !exists {
 		slog.Warn("Unknown project in metadata", "project", projectName)
+		s.metrics.eventIgnored(IgnoreReasonUnknownProject)
+		return
+	}
+
+	// Refuse to control the project from channels it is not allowed in
+	if !project.AllowsChannel(reaction.Event.Item.Channel) {
+		slog.Warn("Project not allowed in channel", "project", projectName, "channel", reaction.Event.Item.Channel)
+		s.sendNotice(ctx, reaction.Event.Item.Channel, reaction.Event.Item.TS, channelNotAllowedMessage(project))
+		return
+	}
+
+	// Include thread_ts and channel to enable posting command output as thread replies in the correct channel
+	req := commandRequest{
+		Project:       project,
+		Action:        action,
+		Command:       withServices(command, services),
+		Services:      services,
+		UserID:        reaction.Event.User,
+		Source:        SourceReaction,
+		SourceChannel: reaction.Event.Item.Channel,
+		Channel:       reaction.Event.Item.Channel,
+		ThreadTS:      reaction.Event.Item.TS,
+	}
+
+	s.submit(ctx, req)
+}
+
+// commandRequest describes a docker compose command to run for a project
+type commandRequest struct {
+	ID            string // Request ID correlating the command's log lines, audit record and output
+	Project       ProjectConfig
+	Action        Action   // Catalog action being run
+	Command       string   // Expanded command sent to Poppit
+	Services      []string // Compose services targeted; empty targets the whole stack
+	UserID        string   // Slack user who triggered the command
+	Source        string   // Entry point: command, reaction or button
+	SourceChannel string   // Slack channel the command was triggered from
+	Channel       string   // Slack channel for the output; empty uses the default channel
+	ThreadTS      string   // Slack message to reply to with the output
+	Ref           string   // Git ref overriding the project's branch for this command
+}
+
+// submit runs a command request through the checks shared by every entry point and dispatches it.
+// Destructive actions wait for confirmation, except buttons, which Slack has already confirmed.
+func (s *Service) submit(ctx context.Context, req commandRequest) {
+	requestID, err := newRequestID()
+	if err != nil {
+		slog.Error("Failed to create request ID", "error", err)
+		return
+	}
+	req.ID = requestID
+
+	// Every command for the project runs with its compose files, profiles, env files and project name
+	req.Command = req.Project.applyComposeOptions(req.Command)
+	// ps output is requested as JSON so it can be rendered as Block Kit
+	req.Command = withPSFormat(req.Command)
+
+	if !s.checkServices(ctx, req) || !s.authorize(ctx, req) {
+		return
+	}
+
+	slog.Info("Executing command for project", "request_id", req.ID, "command", req.Command, "project", req.Project.Name, "user", req.UserID, "source", req.Source)
+
+	if req.Action.Destructive && req.Source != SourceButton {
+		s.requestConfirmation(ctx, req)
 		return
 	}
 
-	slog.Info("Executing command for project", "command", command, "project", projectName)
+	s.dispatch(ctx, req)
+}
+
+// dispatch sends a command request to Poppit, tracks it for correlation with its output
+// and records it in the audit log
+func (s *Service) dispatch(ctx context.Context, req commandRequest) {
+	if req.ID == "" {
+		requestID, err := newRequestID()
+		if err != nil {
+			slog.Error("Failed to create request ID", "error", err)
+			return
+		}
+		req.ID = requestID
+	}
+	requestID := req.ID
+	log := slog.With("request_id", requestID)
+
+	// user and source travel with the command so its outcome can be audited
+	metadata := map[string]interface{}{
+		"request_id": requestID,
+		"project":    req.Project.Name,
+		"user":       req.UserID,
+		"source":     req.Source,
+	}
+	if req.Channel != "" {
+		metadata["channel"] = req.Channel
+	}
+	if req.ThreadTS != "" {
+		metadata["thread_ts"] = req.ThreadTS
+	}
+	if len(req.Services) > 0 {
+		metadata["services"] = req.Services
+	}
 
-	// Send command to Poppit
-	// Include thread_ts and channel metadata to enable posting command output as thread replies in the correct channel
 	poppitPayload := PoppitPayload{
-		Repo:     projectName,
-		Branch:   DefaultGitBranch,
+		Repo:     req.Project.Name,
+		Branch:   req.gitRef(),
 		Type:     "slack-compose",
-		Dir:      project.WorkingDir,
-		Commands: []string{command},
-		Metadata: map[string]interface{}{
-			"project":   projectName,
-			"thread_ts": reaction.Event.Item.TS,
-			"channel":   reaction.Event.Item.Channel,
-		},
+		Dir:      req.Project.WorkingDir,
+		Commands: []string{req.Command},
+		Metadata: metadata,
+	}
+
+	record := AuditRecord{
+		Event:     AuditEventDispatch,
+		RequestID: requestID,
+		UserID:    req.UserID,
+		Channel:   req.SourceChannel,
+		Source:    req.Source,
+		Project:   req.Project.Name,
+		Command:   req.Command,
+		Branch:    poppitPayload.Branch,
+		Outcome:   AuditOutcomeDispatched,
 	}
 
+	sentAt := time.Now()
 	if err := s.sendToPoppit(ctx, poppitPayload); err != nil {
-		slog.Error("Failed to send to Poppit", "error", err, "project", projectName)
+		log.Error("Failed to send to Poppit", "error", err, "project", req.Project.Name)
+		record.Outcome = AuditOutcomeFailed
+		record.Error = err.Error()
+		s.recordAudit(ctx, record)
 		return
 	}
 
-	slog.Info("Sent command to Poppit", "command", command, "project", projectName)
-}
+	s.requests.add(trackedRequest{ID: requestID, Request: req, SentAt: sentAt})
+	s.metrics.dispatched(req.Project.Name, req.Action.Name)
+	s.recordAudit(ctx, record)
 
-// Wait waits for all goroutines to finish
-func (s *Service) Wait() {
-	s.wg.Wait()
+	log.Info("Sent command to Poppit", "command", req.Command, "project", req.Project.Name, "branch", poppitPayload.Branch, "user", req.UserID, "source", req.Source)
 }
 
 // sendBlockKitDialog sends a block kit dialog to the user
@@ -422,55 +636,17 @@ func (s *Service) sendBlockKitDialog(ctx context.Context, channel string) {
 			nil,
 			externalSelect,
 		),
-		// Divider
-		slack.NewDividerBlock(),
-		// Lifecycle actions section header
-		slack.NewSectionBlock(
-			slack.NewTextBlockObject(slack.MarkdownType, "*Lifecycle Actions*", false, false),
-			nil,
-			nil,
-		),
-		// Lifecycle action buttons
-		slack.NewActionBlock(
-			"",
-			slack.NewButtonBlockElement(
-				ActionDockerUp,
-				"up",
-				slack.NewTextBlockObject(slack.PlainTextType, ":arrow_up: Up", true, false),
-			).WithStyle(slack.StylePrimary),
-			slack.NewButtonBlockElement(
-				ActionDockerRestart,
-				"restart",
-				slack.NewTextBlockObject(slack.PlainTextType, ":arrows_counterclockwise: Restart", true, false),
-			),
-			slack.NewButtonBlockElement(
-				ActionDockerDown,
-				"down",
-				slack.NewTextBlockObject(slack.PlainTextType, ":arrow_down: Down", true, false),
-			).WithStyle(slack.StyleDanger),
-		),
-		// Observation section header
-		slack.NewSectionBlock(
-			slack.NewTextBlockObject(slack.MarkdownType, "*Observation*", false, false),
-			nil,
-			nil,
-		),
-		// Observation action buttons
-		slack.NewActionBlock(
-			"",
-			slack.NewButtonBlockElement(
-				ActionDockerPS,
-				"ps",
-				slack.NewTextBlockObject(slack.PlainTextType, ":chart_with_upwards_trend: Process Status", true, false),
-			),
-			slack.NewButtonBlockElement(
-				ActionDockerLogs,
-				"logs",
-				slack.NewTextBlockObject(slack.PlainTextType, ":page_facing_up: View Logs", true, false),
-			),
-		),
 	}
 
+	// Optional picker for targeting individual services
+	if services := s.servicesInput(channel); services != nil {
+		blocks = append(blocks, services)
+	}
+	blocks = append(blocks, slack.NewDividerBlock())
+
+	// A section header and a row of buttons for each action group of the catalog
+	blocks = append(blocks, s.actionBlocks()...)
+
 	slackLinerPayload := SlackLinerPayload{
 		Channel: channel,
 		Blocks:  blocks,
@@ -489,27 +665,208 @@ func (s *Service) sendBlockKitDialog(ctx context.Context, channel string) {
 	slog.Info("Sent block kit dialog", "channel", channel)
 }
 
-// listenForBlockActions listens for Slack block actions from SlackRelay
-func (s *Service) listenForBlockActions(ctx context.Context) {
-	defer s.wg.Done()
+// sendNotice posts a plain text notice to Slack, as a thread reply when threadTS is set
+func (s *Service) sendNotice(ctx context.Context, channel, threadTS, text string) {
+	if channel == "" {
+		channel = s.config.SlackChannel
+	}
+
+	slackLinerPayload := SlackLinerPayload{
+		Channel: channel,
+		Text:    text,
+		Metadata: SlackMetadata{
+			EventType:    "slack-compose-notice",
+			EventPayload: map[string]interface{}{},
+		},
+		TTL:      DefaultTTLSeconds,
+		ThreadTS: threadTS,
+	}
 
-	pubsub := s.redisClient.Subscribe(ctx, s.config.SlackBlockActionsChannel)
-	defer pubsub.Close()
+	if err := s.sendToSlackLiner(ctx, slackLinerPayload); err != nil {
+		slog.Error("Failed to send notice to SlackLiner", "error", err)
+		return
+	}
 
-	slog.Info("Listening for block actions", "channel", s.config.SlackBlockActionsChannel)
+	slog.Debug("Sent notice", "channel", channel, "thread_ts", threadTS)
+}
 
-	ch := pubsub.Channel()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case msg := <-ch:
-			if msg == nil {
-				continue
+// authorize checks that the user's role permits running the requested action on the project,
+// explaining the refusal in Slack when it does not
+func (s *Service) authorize(ctx context.Context, req commandRequest) bool {
+	role := s.config.RoleFor(req.UserID, req.Project)
+	required := req.requiredRole()
+	if role.Allows(required) {
+		return true
+	}
+
+	slog.Warn("User not permitted to run command", "request_id", req.ID, "user", req.UserID, "role", role, "required_role", required, "project", req.Project.Name, "command", req.Command)
+	s.sendNotice(ctx, req.SourceChannel, req.ThreadTS, fmt.Sprintf(":no_entry: <@%s>, your role *%s* on project *%s* does not allow `%s` (requires *%s*).",
+		req.UserID, role, escapeMrkdwn(req.Project.Name), inlineCode(req.Command), required))
+	return false
+}
+
+// checkServices validates the targeted services against the project,
+// explaining the refusal in Slack when they are not known
+func (s *Service) checkServices(ctx context.Context, req commandRequest) bool {
+	if err := req.Project.ValidateServices(req.Services); err != nil {
+		slog.Warn("Invalid service targeting", "request_id", req.ID, "error", err, "project", req.Project.Name, "services", req.Services)
+		s.sendNotice(ctx, req.SourceChannel, req.ThreadTS, fmt.Sprintf(":warning: <@%s>, %s.", req.UserID, escapeMrkdwn(err.Error())))
+		return false
+	}
+	return true
+}
+
+// withServices appends the targeted service names to a command
+func withServices(command string, services []string) string {
+	if len(services) == 0 {
+		return command
+	}
+	return command + " " + strings.Join(services, " ")
+}
+
+// metadataStrings reads a list of strings from message metadata, which arrives as []interface{} after decoding JSON
+func metadataStrings(metadata map[string]interface{}, key string) []string {
+	switch values := metadata[key].(type) {
+	case []string:
+		return values
+	case []interface{}:
+		var result []string
+		for _, v := range values {
+			if str, ok := v.(string); ok && str != "" {
+				result = append(result, str)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// actionNames lists the names of the catalog's actions
+func (s *Service) actionNames() string {
+	var names []string
+	for _, action := range s.config.actions() {
+		names = append(names, fmt.Sprintf("`%s`", action.Name))
+	}
+	return strings.Join(names, ", ")
+}
+
+// servicesInput returns the dialog's optional picker for targeting individual services, offering the
+// services of the projects that can be controlled from the channel. It returns nil when there are none.
+func (s *Service) servicesInput(channel string) *slack.InputBlock {
+	seen := make(map[string]bool)
+	var names []string
+	for _, project := range s.config.projects() {
+		if !project.AllowsChannel(channel) {
+			continue
+		}
+		for _, service := range project.Services {
+			if !seen[service] {
+				seen[service] = true
+				names = append(names, service)
 			}
-			s.handleBlockAction(ctx, msg.Payload)
 		}
 	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	if len(names) > maxServiceOptions {
+		names = names[:maxServiceOptions]
+	}
+
+	options := make([]*slack.OptionBlockObject, 0, len(names))
+	for _, name := range names {
+		options = append(options, slack.NewOptionBlockObject(
+			name,
+			slack.NewTextBlockObject(slack.PlainTextType, name, false, false),
+			nil,
+		))
+	}
+	picker := slack.NewOptionsMultiSelectBlockElement(
+		slack.MultiOptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "All services", false, false),
+		ActionIDServices,
+		options...,
+	)
+	block := slack.NewInputBlock(
+		BlockIDServicesBlock,
+		slack.NewTextBlockObject(slack.PlainTextType, "Services", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Leave empty to target the whole stack; services are checked against the selected project", false, false),
+		picker,
+	)
+	block.Optional = true
+	return block
+}
+
+// channelNotAllowedMessage explains which channels a project may be controlled from
+func channelNotAllowedMessage(project ProjectConfig) string {
+	channels := make([]string, 0, len(project.AllowedChannels))
+	for _, ch := range project.AllowedChannels {
+		channels = append(channels, fmt.Sprintf("<#%s>", escapeMrkdwn(ch)))
+	}
+	return fmt.Sprintf(":no_entry: Project *%s* cannot be controlled from this channel. Allowed channels: %s",
+		escapeMrkdwn(project.Name), strings.Join(channels, ", "))
+}
+
+// actionBlocks builds the dialog's action sections from the action catalog, keeping catalog order
+func (s *Service) actionBlocks() []slack.Block {
+	var groups []string
+	buttons := make(map[string][]slack.BlockElement)
+
+	for _, action := range s.config.actions() {
+		group := action.Group
+		if group == "" {
+			group = DefaultActionGroup
+		}
+		if _, seen := buttons[group]; !seen {
+			groups = append(groups, group)
+		}
+		buttons[group] = append(buttons[group], s.actionButton(action))
+	}
+
+	var blocks []slack.Block
+	for _, group := range groups {
+		blocks = append(blocks,
+			slack.NewSectionBlock(
+				slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s*", group), false, false),
+				nil,
+				nil,
+			),
+			slack.NewActionBlock("", buttons[group]...),
+		)
+	}
+	return blocks
+}
+
+// actionButton creates a dialog button for an action, asking for confirmation when the action is destructive
+func (s *Service) actionButton(action Action) *slack.ButtonBlockElement {
+	button := slack.NewButtonBlockElement(
+		action.ID,
+		action.Name,
+		slack.NewTextBlockObject(slack.PlainTextType, action.Label, true, false),
+	)
+	if action.Style != "" {
+		button = button.WithStyle(slack.Style(action.Style))
+	}
+	if action.Destructive {
+		button = button.WithConfirm(confirmationObject(action.Label, s.expandCommand(action.Command)))
+	}
+	return button
+}
+
+// listenForBlockActions listens for Slack block actions from SlackRelay
+func (s *Service) listenForBlockActions(ctx context.Context) {
+	s.listen(ctx, "block_actions", s.config.SlackBlockActionsChannel, s.handleBlockAction, blockActionEventKey)
+}
+
+// selectedProject returns the project chosen in the dialog's project picker, or "" if none is selected
+func selectedProject(action SlackBlockAction) string {
+	if state, ok := action.State.Values[BlockIDProjectBlock]; ok {
+		if slackCompose, ok := state[ActionIDSlackCompose]; ok && slackCompose.SelectedOption != nil {
+			return slackCompose.SelectedOption.Value
+		}
+	}
+	return ""
 }
 
 // handleBlockAction processes block action events
@@ -517,18 +874,22 @@ func (s *Service) handleBlockAction(ctx context.Context, payload string) {
 	var action SlackBlockAction
 	if err := json.Unmarshal([]byte(payload), &action); err != nil {
 		slog.Error("Failed to parse block action", "error", err)
+		s.metrics.eventIgnored(IgnoreReasonParseError)
 		return
 	}
 
 	slog.Debug("Received block action", "actions", len(action.Actions))
 
 	// Extract the selected project from state
-	projectName := ""
-	if state, ok := action.State.Values[BlockIDProjectBlock]; ok {
-		if slackCompose, ok := state[ActionIDSlackCompose]; ok {
-			if slackCompose.SelectedOption != nil {
-				projectName = slackCompose.SelectedOption.Value
-				slog.Debug("Extracted project from state", "project", projectName)
+	projectName := selectedProject(action)
+	slog.Debug("Extracted project from state", "project", projectName)
+
+	// Extract the targeted services from state
+	var services []string
+	if state, ok := action.State.Values[BlockIDServicesBlock]; ok {
+		if picker, ok := state[ActionIDServices]; ok {
+			for _, option := range picker.SelectedOptions {
+				services = append(services, option.Value)
 			}
 		}
 	}
@@ -540,9 +901,17 @@ func (s *Service) handleBlockAction(ctx context.Context, payload string) {
 	}
 
 	// Check if project exists
-	project, exists := s.config.Projects[projectName]
+	project, exists := s.config.Project(projectName)
 	if !exists {
 		slog.Warn("Unknown project in block action", "project", projectName)
+		s.metrics.eventIgnored(IgnoreReasonUnknownProject)
+		return
+	}
+
+	// Refuse to control the project from channels it is not allowed in
+	if !project.AllowsChannel(action.Channel.ID) {
+		slog.Warn("Project not allowed in channel", "project", projectName, "channel", action.Channel.ID)
+		s.sendNotice(ctx, action.Channel.ID, action.Message.TS, channelNotAllowedMessage(project))
 		return
 	}
 
@@ -551,18 +920,17 @@ func (s *Service) handleBlockAction(ctx context.Context, payload string) {
 		// Only process button actions
 		if act.Type != "button" {
 			slog.Debug("Ignoring non-button action", "type", act.Type)
+			s.metrics.eventIgnored(IgnoreReasonNonButtonAction)
 			continue
 		}
 
 		// Check if this is a known action
-		command, known := s.getCommandForActionID(act.ActionID)
+		composeAction, command, known := s.getCommandForActionID(act.ActionID)
 		if !known {
 			slog.Debug("Unknown action_id, ignoring", "action_id", act.ActionID)
 			continue
 		}
 
-		slog.Info("Executing command for project", "command", command, "project", projectName, "action_id", act.ActionID)
-
 		// Determine channel and thread_ts
 		channel := ""
 		threadTS := ""
@@ -573,25 +941,19 @@ func (s *Service) handleBlockAction(ctx context.Context, payload string) {
 			threadTS = action.Message.TS
 		}
 
-		// Send command to Poppit
-		poppitPayload := PoppitPayload{
-			Repo:     projectName,
-			Branch:   DefaultGitBranch,
-			Type:     "slack-compose",
-			Dir:      project.WorkingDir,
-			Commands: []string{command},
-			Metadata: map[string]interface{}{
-				"project":   projectName,
-				"thread_ts": threadTS,
-				"channel":   channel,
-			},
-		}
-
-		if err := s.sendToPoppit(ctx, poppitPayload); err != nil {
-			slog.Error("Failed to send to Poppit", "error", err, "project", projectName)
-			continue
+		// Send command to Poppit; destructive buttons are confirmed in Slack before the action is sent
+		req := commandRequest{
+			Project:       project,
+			Action:        composeAction,
+			Command:       withServices(command, services),
+			Services:      services,
+			UserID:        action.User.ID,
+			Source:        SourceButton,
+			SourceChannel: action.Channel.ID,
+			Channel:       channel,
+			ThreadTS:      threadTS,
 		}
 
-		slog.Info("Sent command to Poppit", "command", command, "project", projectName)
+		s.submit(ctx, req)
 	}
 }
@@ -4,25 +4,57 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/slack-go/slack"
 )
 
-// mockPubSub is a no-op PubSubInterface used in tests that don't exercise listeners
-type mockPubSub struct{}
+// mockPubSub is a PubSubInterface delivering the messages sent on its channel.
+// Without a channel the subscription is closed straight away.
+type mockPubSub struct {
+	messages   chan *redis.Message
+	receiveErr error
+}
 
 func (m *mockPubSub) Channel(opts ...redis.ChannelOption) <-chan *redis.Message {
+	if m.messages != nil {
+		return m.messages
+	}
 	ch := make(chan *redis.Message)
 	close(ch)
 	return ch
 }
+func (m *mockPubSub) Receive(ctx context.Context) (interface{}, error) {
+	if m.receiveErr != nil {
+		return nil, m.receiveErr
+	}
+	return &redis.Subscription{Kind: "subscribe"}, nil
+}
 func (m *mockPubSub) Close() error { return nil }
 
-// mockRedisClient records RPush calls and optionally injects errors
+// mockRedisClient records RPush and XAdd calls and optionally injects errors.
+// Stream reads return the queued entries once; new-entry reads then block until cancelled.
 type mockRedisClient struct {
 	pushed  []mockPush
 	pushErr error
+	added   []mockXAdd
+	expires map[string]time.Duration
+
+	pendingEntries   []redis.XMessage
+	newEntries       []redis.XMessage
+	claimableEntries []redis.XMessage
+	acked            []string
+	groupCreates     int
+	readErrs         []error // Returned in order by reads of new entries before any entries
+
+	pingErr error
+
+	// subscriptions are returned by Subscribe in order, then a closed one
+	subscriptions []*mockPubSub
+	subscribes    int
 }
 
 type mockPush struct {
@@ -30,7 +62,22 @@ type mockPush struct {
 	value interface{}
 }
 
+type mockXAdd struct {
+	stream string
+	values map[string]interface{}
+}
+
+func (m *mockRedisClient) Ping(ctx context.Context) error {
+	return m.pingErr
+}
+
 func (m *mockRedisClient) Subscribe(ctx context.Context, channel string) PubSubInterface {
+	m.subscribes++
+	if len(m.subscriptions) > 0 {
+		pubsub := m.subscriptions[0]
+		m.subscriptions = m.subscriptions[1:]
+		return pubsub
+	}
 	return &mockPubSub{}
 }
 
@@ -42,16 +89,77 @@ func (m *mockRedisClient) RPush(ctx context.Context, key string, value interface
 	return nil
 }
 
-// mockSlackClient returns configurable GetMessage results
+func (m *mockRedisClient) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if m.expires == nil {
+		m.expires = make(map[string]time.Duration)
+	}
+	m.expires[key] = ttl
+	return nil
+}
+
+func (m *mockRedisClient) XAdd(ctx context.Context, stream string, values map[string]interface{}) error {
+	m.added = append(m.added, mockXAdd{stream: stream, values: values})
+	return nil
+}
+
+func (m *mockRedisClient) XGroupCreateMkStream(ctx context.Context, stream, group string) error {
+	m.groupCreates++
+	return nil
+}
+
+func (m *mockRedisClient) XReadGroup(ctx context.Context, stream, group, consumer, id string, count int64, block time.Duration) ([]redis.XMessage, error) {
+	if id != ">" {
+		entries := m.pendingEntries
+		m.pendingEntries = nil
+		return entries, nil
+	}
+	if len(m.readErrs) > 0 {
+		err := m.readErrs[0]
+		m.readErrs = m.readErrs[1:]
+		return nil, err
+	}
+	if len(m.newEntries) > 0 {
+		entries := m.newEntries
+		m.newEntries = nil
+		return entries, nil
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (m *mockRedisClient) XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, start string, count int64) ([]redis.XMessage, string, error) {
+	entries := m.claimableEntries
+	m.claimableEntries = nil
+	return entries, "0-0", nil
+}
+
+func (m *mockRedisClient) XAck(ctx context.Context, stream, group string, ids ...string) error {
+	m.acked = append(m.acked, ids...)
+	return nil
+}
+
+// mockSlackClient returns configurable GetMessage results and records uploads
 type mockSlackClient struct {
-	message *SlackMessage
-	err     error
+	message   *SlackMessage
+	err       error
+	fetches   int
+	uploads   []SlackFile
+	uploadErr error
 }
 
 func (m *mockSlackClient) GetMessage(ctx context.Context, channel, timestamp string) (*SlackMessage, error) {
+	m.fetches++
 	return m.message, m.err
 }
 
+func (m *mockSlackClient) UploadFile(ctx context.Context, file SlackFile) error {
+	if m.uploadErr != nil {
+		return m.uploadErr
+	}
+	m.uploads = append(m.uploads, file)
+	return nil
+}
+
 // newTestService creates a Service wired with mock dependencies
 func newTestService(rc *mockRedisClient, sc SlackClientInterface) *Service {
 	if rc == nil {
@@ -63,7 +171,8 @@ func newTestService(rc *mockRedisClient, sc SlackClientInterface) *Service {
 		SlackChannel:        "#slack-compose",
 		DockerLogsLineLimit: 100,
 		Projects: map[string]ProjectConfig{
-			"my-project": {Name: "my-project", WorkingDir: "/srv/my-project"},
+			"my-project":   {Name: "my-project", WorkingDir: "/srv/my-project", Services: []string{"web", "worker"}},
+			"prod-project": {Name: "prod-project", WorkingDir: "/srv/prod-project", AllowedChannels: []string{"CPROD"}},
 		},
 	}
 	svc := &Service{config: cfg, redisClient: rc, slackClient: sc}
@@ -79,7 +188,7 @@ func TestExpandCommand(t *testing.T) {
 		input string
 		want  string
 	}{
-		{"docker compose logs", "docker compose logs -n 50"},
+		{"docker compose logs -n {log_lines}", "docker compose logs -n 50"},
 		{"docker compose up -d", "docker compose up -d"},
 		{"docker compose down", "docker compose down"},
 		{"docker compose restart", "docker compose restart"},
@@ -112,7 +221,7 @@ func TestGetCommandForEmoji(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		cmd, ok := svc.getCommandForEmoji(tt.emoji)
+		_, cmd, ok := svc.getCommandForEmoji(tt.emoji)
 		if ok != tt.wantOk {
 			t.Errorf("getCommandForEmoji(%q) ok = %v, want %v", tt.emoji, ok, tt.wantOk)
 		}
@@ -142,7 +251,7 @@ func TestGetCommandForActionID(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		cmd, ok := svc.getCommandForActionID(tt.actionID)
+		_, cmd, ok := svc.getCommandForActionID(tt.actionID)
 		if ok != tt.wantOk {
 			t.Errorf("getCommandForActionID(%q) ok = %v, want %v", tt.actionID, ok, tt.wantOk)
 		}
@@ -181,8 +290,53 @@ func TestHandleCommand_KnownProject(t *testing.T) {
 	if pp.Repo != "my-project" {
 		t.Errorf("Repo = %q, want %q", pp.Repo, "my-project")
 	}
-	if len(pp.Commands) != 1 || pp.Commands[0] != "docker compose ps" {
-		t.Errorf("Commands = %v, want [docker compose ps]", pp.Commands)
+	if len(pp.Commands) != 1 || pp.Commands[0] != "docker compose ps --format json" {
+		t.Errorf("Commands = %v, want [docker compose ps --format json]", pp.Commands)
+	}
+}
+
+func TestHandleCommand_ActionAndServices(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	payload := SlackCommand{
+		Command:   "/slack-compose",
+		Text:      "my-project restart web",
+		ChannelID: "C123",
+	}
+	data, _ := json.Marshal(payload)
+	svc.handleCommand(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push to Redis, got %d", len(rc.pushed))
+	}
+	var pp PoppitPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &pp)
+	if pp.Commands[0] != "docker compose restart web" {
+		t.Errorf("command = %q, want %q", pp.Commands[0], "docker compose restart web")
+	}
+	if services, _ := pp.Metadata["services"].([]interface{}); len(services) != 1 || services[0] != "web" {
+		t.Errorf("services metadata = %v, want [web]", pp.Metadata["services"])
+	}
+}
+
+func TestHandleCommand_UnknownService_SendsNotice(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	payload := SlackCommand{
+		Command:   "/slack-compose",
+		Text:      "my-project restart db",
+		ChannelID: "C123",
+	}
+	data, _ := json.Marshal(payload)
+	svc.handleCommand(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push (notice), got %d", len(rc.pushed))
+	}
+	if rc.pushed[0].key != "slack_messages" {
+		t.Errorf("key = %q, want %q (nothing should be sent to Poppit)", rc.pushed[0].key, "slack_messages")
 	}
 }
 
@@ -219,6 +373,34 @@ func TestHandleCommand_UnknownProject_ShowsDialog(t *testing.T) {
 	}
 }
 
+func TestServicesInput(t *testing.T) {
+	svc := newTestService(nil, nil)
+	svc.config.Projects["api"] = ProjectConfig{Name: "api", Services: []string{"worker", "api"}}
+	svc.config.Projects["prod-project"] = ProjectConfig{Name: "prod-project", Services: []string{"db"}, AllowedChannels: []string{"CPROD"}}
+
+	block := svc.servicesInput("C123")
+	if block == nil {
+		t.Fatal("expected a services picker")
+	}
+	picker, ok := block.Element.(*slack.MultiSelectBlockElement)
+	if !ok || picker.Type != slack.MultiOptTypeStatic || picker.ActionID != ActionIDServices {
+		t.Fatalf("element = %+v, want a static multi select", block.Element)
+	}
+	var values []string
+	for _, option := range picker.Options {
+		values = append(values, option.Value)
+	}
+	// Deduplicated and sorted; db belongs to a project not allowed in the channel
+	if strings.Join(values, " ") != "api web worker" {
+		t.Errorf("options = %v, want [api web worker]", values)
+	}
+
+	svc.config.Projects = map[string]ProjectConfig{"plain": {Name: "plain"}}
+	if block := svc.servicesInput("C123"); block != nil {
+		t.Error("expected no picker when no project lists services")
+	}
+}
+
 func TestHandleCommand_WrongCommand_Ignored(t *testing.T) {
 	rc := &mockRedisClient{}
 	svc := newTestService(rc, nil)
@@ -244,6 +426,46 @@ func TestHandleCommand_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestHandleCommand_ChannelNotAllowed_SendsNotice(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	payload := SlackCommand{Command: "/slack-compose", Text: "prod-project", ChannelID: "C123"}
+	data, _ := json.Marshal(payload)
+	svc.handleCommand(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push (notice), got %d", len(rc.pushed))
+	}
+	if rc.pushed[0].key != "slack_messages" {
+		t.Errorf("pushed to key %q, want %q", rc.pushed[0].key, "slack_messages")
+	}
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+	if slp.Channel != "C123" {
+		t.Errorf("Channel = %q, want %q", slp.Channel, "C123")
+	}
+	if !strings.Contains(slp.Text, "<#CPROD>") {
+		t.Errorf("notice %q should mention the allowed channel", slp.Text)
+	}
+}
+
+func TestHandleCommand_ChannelAllowed(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	payload := SlackCommand{Command: "/slack-compose", Text: "prod-project", ChannelID: "CPROD"}
+	data, _ := json.Marshal(payload)
+	svc.handleCommand(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(rc.pushed))
+	}
+	if rc.pushed[0].key != "poppit:notifications" {
+		t.Errorf("pushed to key %q, want %q", rc.pushed[0].key, "poppit:notifications")
+	}
+}
+
 // ---- handlePoppitOutput ----
 
 func TestHandlePoppitOutput_SendsToSlackLiner(t *testing.T) {
@@ -277,6 +499,31 @@ func TestHandlePoppitOutput_SendsToSlackLiner(t *testing.T) {
 	}
 }
 
+func TestHandlePoppitOutput_CarriesServices(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	out := PoppitCommandOutput{
+		Type:    "slack-compose",
+		Command: "docker compose restart web",
+		Metadata: map[string]interface{}{
+			"project":  "my-project",
+			"services": []interface{}{"web"},
+		},
+	}
+	data, _ := json.Marshal(out)
+	svc.handlePoppitOutput(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push to Redis, got %d", len(rc.pushed))
+	}
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+	if services := metadataStrings(slp.Metadata.EventPayload, "services"); len(services) != 1 || services[0] != "web" {
+		t.Errorf("services metadata = %v, want [web]", slp.Metadata.EventPayload["services"])
+	}
+}
+
 func TestHandlePoppitOutput_WrongType_Ignored(t *testing.T) {
 	rc := &mockRedisClient{}
 	svc := newTestService(rc, nil)
@@ -381,6 +628,71 @@ func TestHandleReaction_KnownEmojiKnownProject(t *testing.T) {
 	}
 }
 
+func TestHandleReaction_CatalogAction(t *testing.T) {
+	rc := &mockRedisClient{}
+	sc := &mockSlackClient{
+		message: &SlackMessage{
+			Metadata: SlackMetadata{
+				EventType:    "slack-compose",
+				EventPayload: map[string]interface{}{"project": "my-project"},
+			},
+		},
+	}
+	svc := newTestService(rc, sc)
+	svc.config.Actions = []Action{
+		{ID: "docker_pull", Name: "pull", Emoji: "arrow_double_down", Label: "Pull", Command: "docker compose pull"},
+	}
+
+	reaction := SlackReaction{
+		Event: SlackReactionEvent{
+			Reaction: "arrow_double_down",
+			Item:     SlackReactionItem{Channel: "C123", TS: "111.222"},
+		},
+	}
+	data, _ := json.Marshal(reaction)
+	svc.handleReaction(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(rc.pushed))
+	}
+	var pp PoppitPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &pp)
+	if pp.Commands[0] != "docker compose pull" {
+		t.Errorf("command = %q, want %q", pp.Commands[0], "docker compose pull")
+	}
+}
+
+func TestHandleReaction_TargetsServicesFromMetadata(t *testing.T) {
+	rc := &mockRedisClient{}
+	sc := &mockSlackClient{
+		message: &SlackMessage{
+			Metadata: SlackMetadata{
+				EventType:    "slack-compose",
+				EventPayload: map[string]interface{}{"project": "my-project", "services": []interface{}{"worker"}},
+			},
+		},
+	}
+	svc := newTestService(rc, sc)
+
+	reaction := SlackReaction{
+		Event: SlackReactionEvent{
+			Reaction: EmojiArrowsCounterClockwise,
+			Item:     SlackReactionItem{Channel: "C123", TS: "111.222"},
+		},
+	}
+	data, _ := json.Marshal(reaction)
+	svc.handleReaction(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(rc.pushed))
+	}
+	var pp PoppitPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &pp)
+	if pp.Commands[0] != "docker compose restart worker" {
+		t.Errorf("command = %q, want %q", pp.Commands[0], "docker compose restart worker")
+	}
+}
+
 func TestHandleReaction_UnsupportedEmoji_Ignored(t *testing.T) {
 	rc := &mockRedisClient{}
 	svc := newTestService(rc, nil)
@@ -438,6 +750,80 @@ func TestHandleReaction_NonSlackComposeMessage_Ignored(t *testing.T) {
 	}
 }
 
+func TestHandleReaction_ChannelNotAllowed_SendsNotice(t *testing.T) {
+	rc := &mockRedisClient{}
+	sc := &mockSlackClient{
+		message: &SlackMessage{
+			Metadata: SlackMetadata{
+				EventType:    "slack-compose",
+				EventPayload: map[string]interface{}{"project": "prod-project"},
+			},
+		},
+	}
+	svc := newTestService(rc, sc)
+
+	reaction := SlackReaction{
+		Event: SlackReactionEvent{
+			Reaction: EmojiDownArrow,
+			Item:     SlackReactionItem{Channel: "C123", TS: "111.222"},
+		},
+	}
+	data, _ := json.Marshal(reaction)
+	svc.handleReaction(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push (notice), got %d", len(rc.pushed))
+	}
+	if rc.pushed[0].key != "slack_messages" {
+		t.Errorf("key = %q, want %q", rc.pushed[0].key, "slack_messages")
+	}
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+	if slp.ThreadTS != "111.222" {
+		t.Errorf("ThreadTS = %q, want %q", slp.ThreadTS, "111.222")
+	}
+}
+
+func TestHandleReaction_RoleDenied_SendsNotice(t *testing.T) {
+	rc := &mockRedisClient{}
+	sc := &mockSlackClient{
+		message: &SlackMessage{
+			Metadata: SlackMetadata{
+				EventType:    "slack-compose",
+				EventPayload: map[string]interface{}{"project": "my-project"},
+			},
+		},
+	}
+	svc := newTestService(rc, sc)
+	svc.config.UserRoles = map[string]Role{"UOPS": RoleOperator}
+	svc.config.DefaultRole = RoleViewer
+
+	reaction := SlackReaction{
+		Event: SlackReactionEvent{
+			User:     "UOPS",
+			Reaction: EmojiDownArrow,
+			Item:     SlackReactionItem{Channel: "C123", TS: "111.222"},
+		},
+	}
+	data, _ := json.Marshal(reaction)
+	svc.handleReaction(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push (notice), got %d", len(rc.pushed))
+	}
+	if rc.pushed[0].key != "slack_messages" {
+		t.Errorf("key = %q, want %q", rc.pushed[0].key, "slack_messages")
+	}
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+	if slp.ThreadTS != "111.222" {
+		t.Errorf("ThreadTS = %q, want %q", slp.ThreadTS, "111.222")
+	}
+	if !strings.Contains(slp.Text, "admin") {
+		t.Errorf("notice %q should mention the required role", slp.Text)
+	}
+}
+
 // ---- handleBlockAction ----
 
 func TestHandleBlockAction_KnownAction(t *testing.T) {
@@ -484,6 +870,47 @@ func TestHandleBlockAction_KnownAction(t *testing.T) {
 	}
 }
 
+func TestHandleBlockAction_ServicesInput(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	action := SlackBlockAction{
+		Type: "block_actions",
+		Actions: []BlockActionElement{
+			{ActionID: ActionDockerLogs, Type: "button", Value: "logs"},
+		},
+		State: BlockActionState{
+			Values: map[string]map[string]BlockActionValue{
+				BlockIDProjectBlock: {
+					ActionIDSlackCompose: {
+						Type:           "external_select",
+						SelectedOption: &BlockActionOption{Value: "my-project"},
+					},
+				},
+				BlockIDServicesBlock: {
+					ActionIDServices: {
+						Type:            "multi_static_select",
+						SelectedOptions: []BlockActionOption{{Value: "web"}, {Value: "worker"}},
+					},
+				},
+			},
+		},
+		Message: BlockActionMessage{TS: "123.456"},
+		Channel: BlockActionChannel{ID: "C789"},
+	}
+	data, _ := json.Marshal(action)
+	svc.handleBlockAction(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(rc.pushed))
+	}
+	var pp PoppitPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &pp)
+	if pp.Commands[0] != "docker compose logs -n 100 web worker" {
+		t.Errorf("command = %q, want %q", pp.Commands[0], "docker compose logs -n 100 web worker")
+	}
+}
+
 func TestHandleBlockAction_NoProjectSelected_Ignored(t *testing.T) {
 	rc := &mockRedisClient{}
 	svc := newTestService(rc, nil)
@@ -551,6 +978,76 @@ func TestHandleBlockAction_NonButtonAction_Ignored(t *testing.T) {
 	}
 }
 
+func TestHandleBlockAction_ChannelNotAllowed_SendsNotice(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	action := SlackBlockAction{
+		Type:    "block_actions",
+		Actions: []BlockActionElement{{ActionID: ActionDockerDown, Type: "button"}},
+		State: BlockActionState{
+			Values: map[string]map[string]BlockActionValue{
+				BlockIDProjectBlock: {
+					ActionIDSlackCompose: {
+						SelectedOption: &BlockActionOption{Value: "prod-project"},
+					},
+				},
+			},
+		},
+		Message: BlockActionMessage{TS: "123.456"},
+		Channel: BlockActionChannel{ID: "C789"},
+	}
+	data, _ := json.Marshal(action)
+	svc.handleBlockAction(context.Background(), string(data))
+
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push (notice), got %d", len(rc.pushed))
+	}
+	if rc.pushed[0].key != "slack_messages" {
+		t.Errorf("key = %q, want %q", rc.pushed[0].key, "slack_messages")
+	}
+}
+
+func TestHandleBlockAction_RoleCheckUsesClickingUser(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+	svc.config.UserRoles = map[string]Role{"UOPS": RoleOperator}
+	svc.config.DefaultRole = RoleViewer
+
+	action := SlackBlockAction{
+		Type: "block_actions",
+		Actions: []BlockActionElement{
+			{ActionID: ActionDockerRestart, Type: "button"},
+			{ActionID: ActionDockerDown, Type: "button"},
+		},
+		State: BlockActionState{
+			Values: map[string]map[string]BlockActionValue{
+				BlockIDProjectBlock: {
+					ActionIDSlackCompose: {
+						SelectedOption: &BlockActionOption{Value: "my-project"},
+					},
+				},
+			},
+		},
+		Message: BlockActionMessage{TS: "123.456"},
+		Channel: BlockActionChannel{ID: "C789"},
+		User:    BlockActionUser{ID: "UOPS"},
+	}
+	data, _ := json.Marshal(action)
+	svc.handleBlockAction(context.Background(), string(data))
+
+	// restart is dispatched, down is refused with a notice
+	if len(rc.pushed) != 2 {
+		t.Fatalf("expected 2 pushes, got %d", len(rc.pushed))
+	}
+	if rc.pushed[0].key != "poppit:notifications" {
+		t.Errorf("first key = %q, want %q", rc.pushed[0].key, "poppit:notifications")
+	}
+	if rc.pushed[1].key != "slack_messages" {
+		t.Errorf("second key = %q, want %q", rc.pushed[1].key, "slack_messages")
+	}
+}
+
 func TestHandleBlockAction_InvalidJSON(t *testing.T) {
 	rc := &mockRedisClient{}
 	svc := newTestService(rc, nil)
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultShutdownTimeoutSeconds is how long in-flight handlers get to finish when the service stops
+	DefaultShutdownTimeoutSeconds = 20
+	// shutdownAbortGrace is how long cancelled handlers get to return before Redis is closed
+	shutdownAbortGrace = 2 * time.Second
+)
+
+// shutdownSummary describes what a shutdown left undone
+type shutdownSummary struct {
+	Drained              bool  // Every listener and handler stopped before the deadline
+	CutOff               int64 // Handlers cancelled because they were still running at the deadline
+	Queued               int64 // Events still waiting for a worker at the deadline, dropped unhandled
+	PendingConfirmations int   // Destructive commands whose confirmation will no longer be accepted
+	AwaitingOutput       int   // Dispatched commands whose output will no longer be posted
+}
+
+// beginWork derives the context handlers run with. It keeps the values of ctx but is not
+// cancelled with it, so handlers in flight when the service stops can finish their pushes.
+func (s *Service) beginWork(ctx context.Context) {
+	s.work, s.abort = context.WithCancel(context.WithoutCancel(ctx))
+}
+
+// handlerContext returns the context an event handler runs with, falling back to the
+// listener's context for services that were never started
+func (s *Service) handlerContext(ctx context.Context) context.Context {
+	if s.work != nil {
+		return s.work
+	}
+	return ctx
+}
+
+// Shutdown waits for the listeners to stop and in-flight handlers to finish once the context
+// passed to Start is cancelled. Handlers still running after timeout are cancelled and get a
+// short grace period to return, so the caller can close Redis safely afterwards.
+func (s *Service) Shutdown(timeout time.Duration) shutdownSummary {
+	summary := shutdownSummary{Drained: waitTimeout(&s.wg, timeout)}
+	if s.abort != nil {
+		defer s.abort()
+	}
+	if !summary.Drained {
+		summary.CutOff = s.inFlight.Load()
+		if s.workers != nil {
+			summary.Queued = s.workers.queued.Load()
+		}
+		slog.Warn("Shutdown deadline passed, cancelling in-flight handlers", "count", summary.CutOff, "queued", summary.Queued, "timeout", timeout)
+		if s.abort != nil {
+			s.abort()
+		}
+		if !waitTimeout(&s.wg, shutdownAbortGrace) {
+			slog.Warn("Handlers did not return after being cancelled", "count", s.inFlight.Load())
+		}
+	}
+
+	s.pendingMu.Lock()
+	s.prunePendingLocked(time.Now())
+	summary.PendingConfirmations = len(s.pending)
+	s.pendingMu.Unlock()
+	summary.AwaitingOutput = s.requests.count()
+
+	slog.Info("Service stopped", "drained", summary.Drained, "cut_off_handlers", summary.CutOff, "dropped_queued_events", summary.Queued,
+		"pending_confirmations", summary.PendingConfirmations, "awaiting_output", summary.AwaitingOutput)
+	return summary
+}
+
+// waitTimeout waits for the group, reporting false if it is still busy after timeout
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// runTestListener runs a pub/sub listener delivering one message to handle, the way Start does
+func runTestListener(svc *Service, rc *mockRedisClient, handle func(context.Context, string)) context.CancelFunc {
+	messages := make(chan *redis.Message, 1)
+	messages <- &redis.Message{Payload: "event"}
+	rc.subscriptions = []*mockPubSub{{messages: messages}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	svc.beginWork(ctx)
+	svc.wg.Add(1)
+	go func() {
+		defer svc.wg.Done()
+		svc.listen(ctx, "commands", "slack-commands", handle, nil)
+	}()
+	return cancel
+}
+
+func TestShutdown_DrainsInFlightHandlers(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+	svc.config.RedisTransport = TransportPubSub
+
+	started := make(chan struct{})
+	var handlerErr error
+	stop := runTestListener(svc, rc, func(ctx context.Context, payload string) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		handlerErr = ctx.Err()
+	})
+	<-started
+	stop()
+
+	summary := svc.Shutdown(2 * time.Second)
+	if !summary.Drained || summary.CutOff != 0 {
+		t.Errorf("summary = %+v, want drained", summary)
+	}
+	if handlerErr != nil {
+		t.Errorf("handler context cancelled while draining: %v", handlerErr)
+	}
+}
+
+func TestShutdown_CancelsHandlersAfterTimeout(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+	svc.config.RedisTransport = TransportPubSub
+	svc.requests.add(trackedRequest{ID: "r1", SentAt: time.Now()})
+
+	started := make(chan struct{})
+	stop := runTestListener(svc, rc, func(ctx context.Context, payload string) {
+		close(started)
+		<-ctx.Done()
+	})
+	<-started
+	stop()
+
+	summary := svc.Shutdown(20 * time.Millisecond)
+	if summary.Drained || summary.CutOff != 1 || summary.AwaitingOutput != 1 {
+		t.Errorf("summary = %+v, want one handler cut off and one command awaiting output", summary)
+	}
+	if svc.inFlight.Load() != 0 {
+		t.Errorf("%d handlers still running after shutdown", svc.inFlight.Load())
+	}
+}
+
+func TestHandleStreamEntry_LeavesEntriesPendingWhenStopping(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	handled := false
+	msg := redis.XMessage{ID: "1-0", Values: map[string]interface{}{StreamPayloadField: "event"}}
+	svc.handleStreamEntry(ctx, "commands", "slack-commands", msg, func(string, func()) { handled = true })
+
+	if handled || len(rc.acked) != 0 {
+		t.Errorf("handled = %v, acked = %v; want the entry left pending", handled, rc.acked)
+	}
+}
@@ -10,6 +10,16 @@ import (
 // SlackClientInterface defines the Slack operations used by the Service
 type SlackClientInterface interface {
 	GetMessage(ctx context.Context, channel, timestamp string) (*SlackMessage, error)
+	UploadFile(ctx context.Context, file SlackFile) error
+}
+
+// SlackFile is a text file shared in a channel, or in a thread when ThreadTS is set
+type SlackFile struct {
+	Channel  string
+	ThreadTS string
+	Filename string
+	Title    string
+	Content  string
 }
 
 // SlackClient wraps the Slack API client
@@ -17,10 +27,10 @@ type SlackClient struct {
 	client *slack.Client
 }
 
-// NewSlackClient creates a new Slack client
-func NewSlackClient(token string) *SlackClient {
+// NewSlackClient creates a new Slack client; options such as slack.OptionAPIURL are passed to slack-go
+func NewSlackClient(token string, options ...slack.Option) *SlackClient {
 	return &SlackClient{
-		client: slack.New(token),
+		client: slack.New(token, options...),
 	}
 }
 
@@ -66,3 +76,20 @@ func (s *SlackClient) GetMessage(ctx context.Context, channel, timestamp string)
 
 	return slackMsg, nil
 }
+
+// UploadFile uploads a text file and shares it in the file's channel or thread
+func (s *SlackClient) UploadFile(ctx context.Context, file SlackFile) error {
+	_, err := s.client.UploadFileContext(ctx, slack.UploadFileParameters{
+		Channel:         file.Channel,
+		ThreadTimestamp: file.ThreadTS,
+		Filename:        file.Filename,
+		Title:           file.Title,
+		Content:         file.Content,
+		FileSize:        len(file.Content),
+		SnippetType:     "text",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	return nil
+}
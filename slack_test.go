@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+// fakeSlackAPI stands in for the Slack Web API's external file upload methods
+type fakeSlackAPI struct {
+	server   *httptest.Server
+	uploaded string
+	complete map[string]string
+}
+
+func newFakeSlackAPI(t *testing.T) *fakeSlackAPI {
+	t.Helper()
+	api := &fakeSlackAPI{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/files.getUploadURLExternal", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"ok":         true,
+			"upload_url": api.server.URL + "/upload",
+			"file_id":    "F123",
+		})
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		api.uploaded = string(data)
+	})
+	mux.HandleFunc("/files.completeUploadExternal", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		api.complete = map[string]string{
+			"channel_id": r.FormValue("channel_id"),
+			"thread_ts":  r.FormValue("thread_ts"),
+			"files":      r.FormValue("files"),
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"ok":    true,
+			"files": []map[string]string{{"id": "F123", "title": "docker compose logs"}},
+		})
+	})
+	api.server = httptest.NewServer(mux)
+	t.Cleanup(api.server.Close)
+	return api
+}
+
+func TestSlackClient_UploadFile(t *testing.T) {
+	api := newFakeSlackAPI(t)
+	client := NewSlackClient("xoxb-test", slack.OptionAPIURL(api.server.URL+"/"))
+
+	err := client.UploadFile(context.Background(), SlackFile{
+		Channel:  "C123",
+		ThreadTS: "123.456",
+		Filename: "my-project-logs-2026-10-16.log",
+		Title:    "docker compose logs",
+		Content:  "line 1\nline 2\n",
+	})
+	if err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+
+	if api.uploaded != "line 1\nline 2\n" {
+		t.Errorf("uploaded content = %q", api.uploaded)
+	}
+	if api.complete["channel_id"] != "C123" || api.complete["thread_ts"] != "123.456" {
+		t.Errorf("upload shared to %+v, want channel C123 thread 123.456", api.complete)
+	}
+}
+
+func TestSlackClient_UploadFile_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "not_in_channel"})
+	}))
+	defer server.Close()
+	client := NewSlackClient("xoxb-test", slack.OptionAPIURL(server.URL+"/"))
+
+	err := client.UploadFile(context.Background(), SlackFile{Channel: "C123", Filename: "out.log", Content: "x"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
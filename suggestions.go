@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// SuggestionResponseTTL bounds how long an unclaimed options response stays in Redis
+	SuggestionResponseTTL = 30 * time.Second
+
+	// maxSuggestionOptions is the most options Slack accepts in an external select response
+	maxSuggestionOptions = 100
+)
+
+// listenForSuggestions listens for project picker queries from SlackRelay
+func (s *Service) listenForSuggestions(ctx context.Context) {
+	s.listen(ctx, "block_suggestions", s.config.SlackSuggestionsChannel, s.handleBlockSuggestion, nil)
+}
+
+// handleBlockSuggestion answers an external select query with the matching projects,
+// pushing the options response to the list SlackRelay is waiting on
+func (s *Service) handleBlockSuggestion(ctx context.Context, payload string) {
+	var suggestion SlackBlockSuggestion
+	if err := json.Unmarshal([]byte(payload), &suggestion); err != nil {
+		slog.Error("Failed to parse block suggestion", "error", err)
+		s.metrics.eventIgnored(IgnoreReasonParseError)
+		return
+	}
+
+	// Only answer the project picker
+	if suggestion.ActionID != ActionIDSlackCompose {
+		slog.Debug("Ignoring block suggestion for another element", "action_id", suggestion.ActionID)
+		return
+	}
+
+	if suggestion.ResponseKey == "" {
+		slog.Warn("Block suggestion has no response key, ignoring")
+		return
+	}
+
+	response := slack.OptionsResponse{Options: s.projectOptions(suggestion.Value, suggestion.Channel.ID)}
+	data, err := json.Marshal(response)
+	if err != nil {
+		slog.Error("Failed to marshal options response", "error", err)
+		return
+	}
+
+	if err := s.pushSuggestionResponse(ctx, suggestion.ResponseKey, data); err != nil {
+		slog.Error("Failed to send options response", "error", err, "response_key", suggestion.ResponseKey)
+		return
+	}
+
+	slog.Debug("Sent project options", "query", suggestion.Value, "options", len(response.Options))
+}
+
+// projectOptions returns the projects whose names contain the query (case-insensitive)
+// and that can be controlled from the channel, sorted by name
+func (s *Service) projectOptions(query, channel string) []*slack.OptionBlockObject {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var names []string
+	for name, project := range s.config.projects() {
+		if channel != "" && !project.AllowsChannel(channel) {
+			continue
+		}
+		if strings.Contains(strings.ToLower(name), query) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) > maxSuggestionOptions {
+		names = names[:maxSuggestionOptions]
+	}
+
+	options := make([]*slack.OptionBlockObject, 0, len(names))
+	for _, name := range names {
+		options = append(options, slack.NewOptionBlockObject(
+			name,
+			slack.NewTextBlockObject(slack.PlainTextType, name, false, false),
+			nil,
+		))
+	}
+	return options
+}
+
+// pushSuggestionResponse pushes an options response to its list, expiring it if nobody claims it
+func (s *Service) pushSuggestionResponse(ctx context.Context, key string, data []byte) error {
+	if err := s.redisClient.RPush(ctx, key, data); err != nil {
+		// Response keys are unique per query, so they share one label
+		s.metrics.rpushFailed("suggestion_responses")
+		return fmt.Errorf("failed to push options response: %w", err)
+	}
+	if err := s.redisClient.Expire(ctx, key, SuggestionResponseTTL); err != nil {
+		return fmt.Errorf("failed to set options response expiry: %w", err)
+	}
+	return nil
+}
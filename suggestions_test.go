@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func suggestionPayload(actionID, query, channel, responseKey string) string {
+	data, _ := json.Marshal(SlackBlockSuggestion{
+		Type:        "block_suggestion",
+		ActionID:    actionID,
+		BlockID:     BlockIDProjectBlock,
+		Value:       query,
+		Channel:     BlockActionChannel{ID: channel},
+		ResponseKey: responseKey,
+	})
+	return string(data)
+}
+
+func optionValues(t *testing.T, rc *mockRedisClient) []string {
+	t.Helper()
+	if len(rc.pushed) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(rc.pushed))
+	}
+	var response slack.OptionsResponse
+	if err := json.Unmarshal(rc.pushed[0].value.([]byte), &response); err != nil {
+		t.Fatalf("failed to unmarshal options response: %v", err)
+	}
+	var values []string
+	for _, option := range response.Options {
+		values = append(values, option.Value)
+	}
+	return values
+}
+
+func TestHandleBlockSuggestion_FiltersByQuery(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	svc.handleBlockSuggestion(context.Background(), suggestionPayload(ActionIDSlackCompose, "MY", "CPROD", "suggest:1"))
+
+	if rc.pushed[0].key != "suggest:1" {
+		t.Errorf("key = %q, want %q", rc.pushed[0].key, "suggest:1")
+	}
+	if values := optionValues(t, rc); len(values) != 1 || values[0] != "my-project" {
+		t.Errorf("options = %v, want [my-project]", values)
+	}
+	if rc.expires["suggest:1"] != SuggestionResponseTTL {
+		t.Errorf("expiry = %v, want %v", rc.expires["suggest:1"], SuggestionResponseTTL)
+	}
+}
+
+func TestHandleBlockSuggestion_EmptyQueryListsAllowedProjects(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+
+	// prod-project is restricted to CPROD
+	svc.handleBlockSuggestion(context.Background(), suggestionPayload(ActionIDSlackCompose, "", "C123", "suggest:1"))
+	if values := optionValues(t, rc); len(values) != 1 || values[0] != "my-project" {
+		t.Errorf("options = %v, want [my-project]", values)
+	}
+
+	rc.pushed = nil
+	svc.handleBlockSuggestion(context.Background(), suggestionPayload(ActionIDSlackCompose, "", "CPROD", "suggest:2"))
+	if values := optionValues(t, rc); len(values) != 2 || values[0] != "my-project" || values[1] != "prod-project" {
+		t.Errorf("options = %v, want [my-project prod-project]", values)
+	}
+}
+
+func TestHandleBlockSuggestion_LimitsOptions(t *testing.T) {
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, nil)
+	for i := 0; i < maxSuggestionOptions+10; i++ {
+		name := fmt.Sprintf("project-%03d", i)
+		svc.config.Projects[name] = ProjectConfig{Name: name}
+	}
+
+	svc.handleBlockSuggestion(context.Background(), suggestionPayload(ActionIDSlackCompose, "project-", "C123", "suggest:1"))
+
+	if values := optionValues(t, rc); len(values) != maxSuggestionOptions {
+		t.Errorf("got %d options, want %d", len(values), maxSuggestionOptions)
+	}
+}
+
+func TestHandleBlockSuggestion_Ignored(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{"other element", suggestionPayload("other_select", "my", "C123", "suggest:1")},
+		{"no response key", suggestionPayload(ActionIDSlackCompose, "my", "C123", "")},
+		{"invalid JSON", "not json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &mockRedisClient{}
+			svc := newTestService(rc, nil)
+
+			svc.handleBlockSuggestion(context.Background(), tt.payload)
+
+			if len(rc.pushed) != 0 {
+				t.Errorf("expected no pushes, got %d", len(rc.pushed))
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Redis transports for incoming events
+	TransportPubSub  = "pubsub"
+	TransportStreams = "streams"
+
+	// StreamPayloadField is the stream entry field holding the event JSON
+	StreamPayloadField = "payload"
+
+	// Streams transport tuning
+	streamReadCount = 10
+	streamReadBlock = 5 * time.Second
+
+	// reconnectInitialBackoff is the wait before the first attempt to restore a listener
+	reconnectInitialBackoff = 500 * time.Millisecond
+	// DefaultRedisReconnectMaxSeconds caps the wait between attempts to restore a listener
+	DefaultRedisReconnectMaxSeconds = 30
+)
+
+// backoff computes exponentially growing waits with jitter between reconnection attempts
+type backoff struct {
+	initial  time.Duration
+	max      time.Duration
+	attempts int
+}
+
+// reconnectBackoff returns the backoff used by listeners to restore their subscription
+func (s *Service) reconnectBackoff() *backoff {
+	max := time.Duration(s.config.RedisReconnectMaxSeconds) * time.Second
+	if max <= 0 {
+		max = DefaultRedisReconnectMaxSeconds * time.Second
+	}
+	return &backoff{initial: reconnectInitialBackoff, max: max}
+}
+
+// next returns the wait before the next attempt: the initial wait doubled for every previous
+// attempt, capped at max, with a random half of it taken off so listeners don't retry in step
+func (b *backoff) next() time.Duration {
+	wait := b.max
+	if b.attempts < 32 && b.initial<<b.attempts < b.max {
+		wait = b.initial << b.attempts
+	}
+	b.attempts++
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// reset starts the backoff over once a connection has been restored
+func (b *backoff) reset() {
+	b.attempts = 0
+}
+
+// reconnect records that a listener lost its subscription, then waits before the next attempt
+func (s *Service) reconnect(ctx context.Context, name, channel string, err error, retry *backoff) {
+	wait := retry.next()
+	slog.Warn("Listener lost its subscription, reconnecting", "error", err, "listener", name, "channel", channel,
+		"attempt", retry.attempts, "backoff", wait)
+	s.listeners.reconnecting(name, err)
+	s.metrics.listenerReconnected(name)
+	sleepContext(ctx, wait)
+}
+
+// deliverFunc hands an event payload to the workers; done, if set, runs once it has been handled
+type deliverFunc func(payload string, done func())
+
+// stagedHandler handles the first part of an event. It returns the rest of the handling, or nil
+// when there is none, with the key whose worker must run it.
+type stagedHandler func(ctx context.Context, payload string) (string, func(context.Context))
+
+// listen consumes events from a Redis channel (or stream) and passes each payload to handle on
+// the worker pool. key names the project (or other key) whose events must be handled in order;
+// nil handles every event as soon as a worker is free.
+func (s *Service) listen(ctx context.Context, name, channel string, handle func(context.Context, string), key func(string) string) {
+	s.listenStaged(ctx, name, channel, func(ctx context.Context, payload string) (string, func(context.Context)) {
+		handle(ctx, payload)
+		return "", nil
+	}, key)
+}
+
+// listenStaged is listen for events whose key is only known once part of them is handled, such
+// as reactions: handle runs on the worker for key, then hands the rest to the worker it names
+func (s *Service) listenStaged(ctx context.Context, name, channel string, handle stagedHandler, key func(string) string) {
+	// Every listener reports its subscription and message count to the health endpoints
+	s.listeners.register(name, channel)
+	deliver := func(payload string, done func()) {
+		s.listeners.received(name)
+		s.metrics.eventReceived(channel)
+		if done == nil {
+			done = func() {}
+		}
+
+		var eventKey string
+		if key != nil && s.workers != nil {
+			eventKey = key(payload)
+		}
+		worker := s.workers.worker(eventKey)
+
+		run := func() {
+			s.inFlight.Add(1)
+			nextKey, next := handle(s.handlerContext(ctx), payload)
+			s.inFlight.Add(-1)
+			if next == nil {
+				done()
+				return
+			}
+
+			rest := func() {
+				s.inFlight.Add(1)
+				defer s.inFlight.Add(-1)
+				next(s.handlerContext(ctx))
+				done()
+			}
+			if !s.workers.handoff(worker, nextKey, rest) {
+				s.logDroppedEvent(name, nextKey)
+			}
+		}
+
+		if !s.workers.submitTo(ctx, worker, run) && ctx.Err() == nil {
+			s.logDroppedEvent(name, eventKey)
+		}
+	}
+
+	if s.config.RedisTransport == TransportStreams {
+		s.consumeStream(ctx, name, channel, deliver)
+		return
+	}
+	s.subscribe(ctx, name, channel, deliver)
+}
+
+// subscribe handles events published on a Redis pub/sub channel, resubscribing with backoff
+// whenever the subscription dies. Events published while the service is not subscribed are lost.
+func (s *Service) subscribe(ctx context.Context, name, channel string, deliver deliverFunc) {
+	slog.Info("Listening for events", "listener", name, "channel", channel, "transport", TransportPubSub)
+	defer s.listeners.setSubscribed(name, false)
+
+	retry := s.reconnectBackoff()
+	for {
+		err := s.receiveMessages(ctx, name, channel, deliver, retry)
+		if ctx.Err() != nil {
+			return
+		}
+		s.reconnect(ctx, name, channel, err, retry)
+	}
+}
+
+// errSubscriptionClosed is returned by receiveMessages when Redis closes the message channel
+var errSubscriptionClosed = errors.New("subscription closed")
+
+// receiveMessages subscribes to the channel and handles its messages until the context is
+// cancelled or the subscription dies, returning why it died
+func (s *Service) receiveMessages(ctx context.Context, name, channel string, deliver deliverFunc, retry *backoff) error {
+	pubsub := s.redisClient.Subscribe(ctx, channel)
+	defer pubsub.Close()
+
+	// Wait for Redis to confirm the subscription before reporting the listener as subscribed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to confirm subscription: %w", err)
+	}
+	s.listeners.setSubscribed(name, true)
+	retry.reset()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return errSubscriptionClosed
+			}
+			if msg == nil {
+				continue
+			}
+			deliver(msg.Payload, nil)
+		}
+	}
+}
+
+// consumeStream handles events from a Redis stream as a member of a consumer group.
+// Entries are acknowledged only after they have been handled, so events published
+// while the service is down are processed once it is back.
+func (s *Service) consumeStream(ctx context.Context, name, stream string, deliver deliverFunc) {
+	group := s.config.RedisConsumerGroup
+	retry := s.reconnectBackoff()
+
+	if !s.createConsumerGroup(ctx, name, stream, retry) {
+		return
+	}
+	retry.reset()
+
+	slog.Info("Listening for events", "listener", name, "stream", stream, "group", group,
+		"consumer", s.config.RedisConsumerName, "transport", TransportStreams)
+	s.listeners.setSubscribed(name, true)
+	defer s.listeners.setSubscribed(name, false)
+
+	// Entries delivered to this consumer before a restart but never acknowledged
+	s.drainPendingEntries(ctx, name, stream, deliver)
+
+	// Entries abandoned by other consumers, e.g. a previous container with another name
+	s.reclaimIdleEntries(ctx, name, stream, deliver)
+
+	for ctx.Err() == nil {
+		messages, err := s.redisClient.XReadGroup(ctx, stream, group, s.config.RedisConsumerName, ">", streamReadCount, streamReadBlock)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.reconnect(ctx, name, stream, fmt.Errorf("failed to read from stream: %w", err), retry)
+			// The stream or group may be gone, e.g. after a Redis restart without persistence or a
+			// FLUSHALL; reads then fail with NOGROUP until the group is created again
+			if !s.createConsumerGroup(ctx, name, stream, retry) {
+				return
+			}
+			continue
+		}
+		s.listeners.setSubscribed(name, true)
+		retry.reset()
+
+		for _, msg := range messages {
+			s.handleStreamEntry(ctx, name, stream, msg, deliver)
+		}
+	}
+}
+
+// createConsumerGroup creates the consumer group, and the stream if needed, retrying with backoff
+// until it succeeds. An existing group is left as it is. It reports false once ctx is cancelled.
+func (s *Service) createConsumerGroup(ctx context.Context, name, stream string, retry *backoff) bool {
+	group := s.config.RedisConsumerGroup
+	for {
+		err := s.redisClient.XGroupCreateMkStream(ctx, stream, group)
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		s.reconnect(ctx, name, stream, fmt.Errorf("failed to create consumer group %s: %w", group, err), retry)
+	}
+}
+
+// drainPendingEntries re-handles entries this consumer read but never acknowledged.
+// Entries stay pending until a worker has handled them, so each read starts after the last one seen.
+func (s *Service) drainPendingEntries(ctx context.Context, name, stream string, deliver deliverFunc) {
+	start := "0"
+	for ctx.Err() == nil {
+		messages, err := s.redisClient.XReadGroup(ctx, stream, s.config.RedisConsumerGroup, s.config.RedisConsumerName, start, streamReadCount, 0)
+		if err != nil {
+			slog.Error("Failed to read pending stream entries", "error", err, "listener", name, "stream", stream)
+			return
+		}
+		if len(messages) == 0 {
+			return
+		}
+
+		slog.Info("Processing pending stream entries", "listener", name, "stream", stream, "count", len(messages))
+		for _, msg := range messages {
+			s.handleStreamEntry(ctx, name, stream, msg, deliver)
+		}
+		start = messages[len(messages)-1].ID
+	}
+}
+
+// reclaimIdleEntries claims and handles entries left pending by other consumers
+func (s *Service) reclaimIdleEntries(ctx context.Context, name, stream string, deliver deliverFunc) {
+	minIdle := time.Duration(s.config.RedisClaimMinIdleSeconds) * time.Second
+	start := "0-0"
+
+	for ctx.Err() == nil {
+		messages, next, err := s.redisClient.XAutoClaim(ctx, stream, s.config.RedisConsumerGroup, s.config.RedisConsumerName, minIdle, start, streamReadCount)
+		if err != nil {
+			slog.Error("Failed to reclaim stream entries", "error", err, "listener", name, "stream", stream)
+			return
+		}
+
+		if len(messages) > 0 {
+			slog.Info("Reclaimed idle stream entries", "listener", name, "stream", stream, "count", len(messages))
+		}
+		for _, msg := range messages {
+			s.handleStreamEntry(ctx, name, stream, msg, deliver)
+		}
+
+		if next == "0-0" || next == "" {
+			return
+		}
+		start = next
+	}
+}
+
+// handleStreamEntry delivers a stream entry to the workers, acknowledging it once it has been handled.
+// Entries without a payload (including ones trimmed from the stream) are acknowledged and dropped.
+// Once the service is stopping, entries are left pending so they are handled after a restart.
+func (s *Service) handleStreamEntry(ctx context.Context, name, stream string, msg redis.XMessage, deliver deliverFunc) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Acknowledge with the handlers' context so entries handled during shutdown aren't redelivered
+	ack := func() {
+		if err := s.redisClient.XAck(s.handlerContext(ctx), stream, s.config.RedisConsumerGroup, msg.ID); err != nil {
+			slog.Error("Failed to acknowledge stream entry", "error", err, "listener", name, "stream", stream, "id", msg.ID)
+		}
+	}
+
+	payload, ok := msg.Values[StreamPayloadField].(string)
+	if !ok {
+		slog.Warn("Stream entry has no payload, dropping", "listener", name, "stream", stream, "id", msg.ID)
+		ack()
+		return
+	}
+	deliver(payload, ack)
+}
+
+// sleepContext waits for the duration or until the context is cancelled
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestConsumeStream_HandlesPendingClaimedAndNewEntries(t *testing.T) {
+	rc := &mockRedisClient{
+		pendingEntries:   []redis.XMessage{{ID: "1-0", Values: map[string]interface{}{StreamPayloadField: "pending"}}},
+		claimableEntries: []redis.XMessage{{ID: "2-0", Values: map[string]interface{}{StreamPayloadField: "claimed"}}},
+		newEntries: []redis.XMessage{
+			{ID: "3-0", Values: map[string]interface{}{StreamPayloadField: "new"}},
+			{ID: "4-0", Values: map[string]interface{}{}},
+		},
+	}
+	svc := newTestService(rc, nil)
+	svc.config.RedisTransport = TransportStreams
+	svc.config.RedisConsumerGroup = "slackcompose"
+	svc.config.RedisConsumerName = "test"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.listen(ctx, "commands", "slack-commands", func(ctx context.Context, payload string) {
+			handled = append(handled, payload)
+			if payload == "new" {
+				// Let the listener acknowledge the remaining entries before stopping
+				time.AfterFunc(10*time.Millisecond, cancel)
+			}
+		}, nil)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("listener did not stop after cancellation")
+	}
+
+	want := []string{"pending", "claimed", "new"}
+	if len(handled) != len(want) {
+		t.Fatalf("handled = %v, want %v", handled, want)
+	}
+	for i := range want {
+		if handled[i] != want[i] {
+			t.Errorf("handled[%d] = %q, want %q", i, handled[i], want[i])
+		}
+	}
+
+	// Every entry is acknowledged, including the one without a payload
+	wantAcked := []string{"1-0", "2-0", "3-0", "4-0"}
+	if len(rc.acked) != len(wantAcked) {
+		t.Fatalf("acked = %v, want %v", rc.acked, wantAcked)
+	}
+	for i := range wantAcked {
+		if rc.acked[i] != wantAcked[i] {
+			t.Errorf("acked[%d] = %q, want %q", i, rc.acked[i], wantAcked[i])
+		}
+	}
+}
+
+func TestConsumeStream_RecreatesGroupAfterNOGROUP(t *testing.T) {
+	rc := &mockRedisClient{
+		readErrs:   []error{errors.New("NOGROUP No such key 'slack-commands' or consumer group 'slackcompose' in XREADGROUP with GROUP option")},
+		newEntries: []redis.XMessage{{ID: "1-0", Values: map[string]interface{}{StreamPayloadField: "after recovery"}}},
+	}
+	svc := newTestService(rc, nil)
+	svc.config.RedisTransport = TransportStreams
+	svc.config.RedisConsumerGroup = "slackcompose"
+	svc.config.RedisConsumerName = "test"
+	svc.config.RedisReconnectMaxSeconds = 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.listen(ctx, "commands", "slack-commands", func(ctx context.Context, payload string) {
+			handled = append(handled, payload)
+			time.AfterFunc(10*time.Millisecond, cancel)
+		}, nil)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("listener did not recover from NOGROUP")
+	}
+
+	if len(handled) != 1 || handled[0] != "after recovery" {
+		t.Errorf("handled = %v, want [after recovery]", handled)
+	}
+	if rc.groupCreates != 2 {
+		t.Errorf("created the consumer group %d times, want 2", rc.groupCreates)
+	}
+	if len(rc.acked) != 1 || rc.acked[0] != "1-0" {
+		t.Errorf("acked = %v, want [1-0]", rc.acked)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := &backoff{initial: 100 * time.Millisecond, max: time.Second}
+	for _, ceiling := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		ceiling *= time.Millisecond
+		if wait := b.next(); wait < ceiling/2 || wait > ceiling {
+			t.Errorf("attempt %d waited %v, want between %v and %v", b.attempts, wait, ceiling/2, ceiling)
+		}
+	}
+
+	b.reset()
+	if wait := b.next(); wait > 100*time.Millisecond {
+		t.Errorf("after reset waited %v, want at most 100ms", wait)
+	}
+}
+
+func TestSubscribe_ResubscribesWhenSubscriptionDies(t *testing.T) {
+	closing := make(chan *redis.Message, 1)
+	closing <- &redis.Message{Payload: "first"}
+	close(closing)
+	open := make(chan *redis.Message, 1)
+	open <- &redis.Message{Payload: "second"}
+
+	rc := &mockRedisClient{subscriptions: []*mockPubSub{
+		{receiveErr: errors.New("connection refused")},
+		{messages: closing},
+		{messages: open},
+	}}
+	svc := newTestService(rc, nil)
+	svc.config.RedisTransport = TransportPubSub
+	svc.metrics = newMetrics()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled []string
+	var status listenerStatus
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.listen(ctx, "commands", "slack-commands", func(ctx context.Context, payload string) {
+			handled = append(handled, payload)
+			if payload == "second" {
+				status = svc.listeners.snapshot()[0]
+				cancel()
+			}
+		}, nil)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("listener did not resubscribe")
+	}
+
+	if len(handled) != 2 || handled[0] != "first" || handled[1] != "second" {
+		t.Errorf("handled = %v, want [first second]", handled)
+	}
+	if rc.subscribes != 3 {
+		t.Errorf("subscribed %d times, want 3", rc.subscribes)
+	}
+	if !status.Subscribed || status.Reconnects != 2 || status.LastError != "" {
+		t.Errorf("status after resubscribing = %+v", status)
+	}
+	if got := testutil.ToFloat64(svc.metrics.reconnects.WithLabelValues("commands")); got != 2 {
+		t.Errorf("reconnects metric = %v, want 2", got)
+	}
+}
@@ -81,6 +81,7 @@ type SlackBlockAction struct {
 	State   BlockActionState     `json:"state"`
 	Message BlockActionMessage   `json:"message,omitempty"`
 	Channel BlockActionChannel   `json:"channel,omitempty"`
+	User    BlockActionUser      `json:"user,omitempty"`
 }
 
 // BlockActionElement represents an individual action element
@@ -98,8 +99,9 @@ type BlockActionState struct {
 
 // BlockActionValue represents a value from a block action
 type BlockActionValue struct {
-	Type           string             `json:"type"`
-	SelectedOption *BlockActionOption `json:"selected_option"`
+	Type            string              `json:"type"`
+	SelectedOption  *BlockActionOption  `json:"selected_option"`
+	SelectedOptions []BlockActionOption `json:"selected_options,omitempty"` // Choices of multi select elements
 }
 
 // BlockActionOption represents a selected option
@@ -125,3 +127,24 @@ type BlockActionChannel struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
+
+// SlackBlockSuggestion represents a query typed into an external select, as relayed by SlackRelay
+type SlackBlockSuggestion struct {
+	Type     string             `json:"type"`
+	ActionID string             `json:"action_id"`
+	BlockID  string             `json:"block_id"`
+	Value    string             `json:"value"` // Text typed by the user
+	Channel  BlockActionChannel `json:"channel,omitempty"`
+	User     BlockActionUser    `json:"user,omitempty"`
+
+	// ResponseKey is the Redis list SlackRelay waits on for the options response
+	ResponseKey string `json:"response_key"`
+}
+
+// BlockActionUser represents the user who triggered the block action
+type BlockActionUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	TeamID   string `json:"team_id"`
+}
@@ -186,7 +186,8 @@ func TestSlackBlockAction_JSONUnmarshal(t *testing.T) {
 			}
 		},
 		"message": {"ts": "1234567890.123456"},
-		"channel": {"id": "C1234567890", "name": "slack-compose"}
+		"channel": {"id": "C1234567890", "name": "slack-compose"},
+		"user": {"id": "U0123", "username": "alice", "name": "alice", "team_id": "T0001"}
 	}`
 
 	var action SlackBlockAction
@@ -206,6 +207,9 @@ func TestSlackBlockAction_JSONUnmarshal(t *testing.T) {
 	if action.Channel.ID != "C1234567890" {
 		t.Errorf("Channel.ID = %q, want %q", action.Channel.ID, "C1234567890")
 	}
+	if action.User.ID != "U0123" {
+		t.Errorf("User.ID = %q, want %q", action.User.ID, "U0123")
+	}
 	if action.Message.TS != "1234567890.123456" {
 		t.Errorf("Message.TS = %q, want %q", action.Message.TS, "1234567890.123456")
 	}
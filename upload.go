@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultOutputUploadThreshold is the output size in bytes above which the output is uploaded as a file
+	DefaultOutputUploadThreshold = 10000
+	// DefaultOutputPreviewLines is the number of lines from the start and end of uploaded output shown inline
+	DefaultOutputPreviewLines = 5
+)
+
+// shouldUpload reports whether output is large enough to be attached as a file rather than posted inline
+func (s *Service) shouldUpload(output string) bool {
+	return s.slackClient != nil && s.config.OutputUploadThreshold > 0 && len(output) > s.config.OutputUploadThreshold
+}
+
+// uploadOutput attaches the full output as a file snippet in the channel or thread and
+// returns the section to post inline instead: a preview of the first and last lines
+func (s *Service) uploadOutput(ctx context.Context, channel, threadTS, projectName, command, output string) (outputSection, error) {
+	file := SlackFile{
+		Channel:  channel,
+		ThreadTS: threadTS,
+		Filename: outputFilename(projectName, command, time.Now()),
+		Title:    command,
+		Content:  output,
+	}
+	if err := s.slackClient.UploadFile(ctx, file); err != nil {
+		s.metrics.slackAPIError(SlackMethodUploadFile)
+		return outputSection{}, err
+	}
+	slog.Info("Uploaded output as a file", "project", projectName, "filename", file.Filename, "bytes", len(output))
+
+	preview, total := previewOutput(output, s.config.OutputPreviewLines)
+	return outputSection{
+		Title: fmt.Sprintf("_Full output (%d lines) attached as `%s`:_", total, file.Filename),
+		Body:  preview,
+	}, nil
+}
+
+// outputFilename names an uploaded output file after the project, compose command and date,
+// e.g. myproj-logs-2026-10-16.log
+func outputFilename(projectName, command string, now time.Time) string {
+	if projectName == "" {
+		projectName = "slack-compose"
+	}
+	verb := composeVerb(command)
+	if verb == "" {
+		verb = "output"
+	}
+	return fmt.Sprintf("%s-%s-%s.log", projectName, verb, now.Format("2006-01-02"))
+}
+
+// previewOutput keeps the first and last lines of the output, noting how many were left out
+// in between, and returns the preview with the total number of lines
+func previewOutput(output string, lines int) (string, int) {
+	if lines <= 0 {
+		lines = DefaultOutputPreviewLines
+	}
+
+	all := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(all) <= 2*lines {
+		return strings.Join(all, "\n"), len(all)
+	}
+
+	preview := append([]string{}, all[:lines]...)
+	preview = append(preview, fmt.Sprintf("… %d lines …", len(all)-2*lines))
+	preview = append(preview, all[len(all)-lines:]...)
+	return strings.Join(preview, "\n"), len(all)
+}
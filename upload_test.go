@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutputFilename(t *testing.T) {
+	date := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		project string
+		command string
+		want    string
+	}{
+		{"myproj", "docker compose logs -n 100", "myproj-logs-2026-10-16.log"},
+		{"myproj", "docker compose -f prod.yml up -d", "myproj-up-2026-10-16.log"},
+		{"", "make deploy", "slack-compose-output-2026-10-16.log"},
+	}
+
+	for _, tt := range tests {
+		if got := outputFilename(tt.project, tt.command, date); got != tt.want {
+			t.Errorf("outputFilename(%q, %q) = %q, want %q", tt.project, tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestPreviewOutput(t *testing.T) {
+	preview, total := previewOutput(numberedLines(20), 2)
+	if total != 20 {
+		t.Errorf("total = %d, want 20", total)
+	}
+	lines := strings.Split(preview, "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "line 0001") || lines[2] != "… 16 lines …" || !strings.HasPrefix(lines[4], "line 0020") {
+		t.Errorf("preview = %q", preview)
+	}
+
+	if preview, _ := previewOutput("a\nb\n", 2); preview != "a\nb" {
+		t.Errorf("short output should be kept whole, got %q", preview)
+	}
+}
+
+func handleLargeOutput(t *testing.T, sc *mockSlackClient) (*mockRedisClient, SlackLinerPayload) {
+	t.Helper()
+	rc := &mockRedisClient{}
+	svc := newTestService(rc, sc)
+	svc.config.OutputUploadThreshold = 1000
+	svc.config.OutputPreviewLines = 3
+
+	data, _ := json.Marshal(PoppitCommandOutput{
+		Type:     "slack-compose",
+		Command:  "docker compose logs -n 100",
+		Output:   numberedLines(100),
+		Metadata: map[string]interface{}{"project": "my-project", "channel": "C123", "thread_ts": "123.456"},
+	})
+	svc.handlePoppitOutput(context.Background(), string(data))
+
+	if len(rc.pushed) == 0 {
+		t.Fatal("expected a message to SlackLiner")
+	}
+	var slp SlackLinerPayload
+	json.Unmarshal(rc.pushed[0].value.([]byte), &slp)
+	return rc, slp
+}
+
+func TestHandlePoppitOutput_UploadsLargeOutput(t *testing.T) {
+	sc := &mockSlackClient{}
+	rc, slp := handleLargeOutput(t, sc)
+
+	if len(sc.uploads) != 1 {
+		t.Fatalf("expected 1 upload, got %d", len(sc.uploads))
+	}
+	file := sc.uploads[0]
+	if file.Channel != "C123" || file.ThreadTS != "123.456" {
+		t.Errorf("file shared to %s/%s, want C123/123.456", file.Channel, file.ThreadTS)
+	}
+	if !strings.HasPrefix(file.Filename, "my-project-logs-") || file.Content != numberedLines(100) {
+		t.Errorf("unexpected file %q with %d bytes", file.Filename, len(file.Content))
+	}
+
+	// Only the preview is posted inline
+	if len(rc.pushed) != 1 {
+		t.Errorf("expected 1 message, got %d", len(rc.pushed))
+	}
+	if !strings.Contains(slp.Text, "attached as `"+file.Filename+"`") || !strings.Contains(slp.Text, "… 94 lines …") {
+		t.Errorf("text = %q", slp.Text)
+	}
+	if strings.Contains(slp.Text, "line 0050") {
+		t.Error("the middle of the output should not be posted inline")
+	}
+}
+
+func TestHandlePoppitOutput_UploadFailureFallsBackToText(t *testing.T) {
+	sc := &mockSlackClient{uploadErr: errors.New("not_in_channel")}
+	rc, slp := handleLargeOutput(t, sc)
+
+	if strings.Contains(slp.Text, "attached") {
+		t.Errorf("text should not mention an attachment, got %q", slp.Text)
+	}
+	// The whole output is posted, split over several messages
+	if len(rc.pushed) < 2 || !strings.Contains(slp.Text, "line 0001") {
+		t.Errorf("expected the output inline over several messages, got %d", len(rc.pushed))
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DefaultWorkerPoolSize is the number of workers handling events concurrently
+	DefaultWorkerPoolSize = 4
+	// DefaultWorkerQueueSize is the number of events each worker can have waiting
+	DefaultWorkerQueueSize = 100
+
+	// Policies for an event arriving when its worker's queue is full
+	QueueFullBlock = "block" // The listener waits for room, leaving later events in Redis
+	QueueFullDrop  = "drop"  // The event is dropped, logged and counted
+)
+
+// workerPool handles events on a fixed number of workers. Events with the same key always go
+// to the same worker and are handled strictly in the order they were submitted; events with
+// different keys can run in parallel. A job may hand the rest of its event over to the worker
+// of another key. A nil *workerPool runs every job inline.
+type workerPool struct {
+	queues []chan func()
+	policy string
+	wg     sync.WaitGroup
+
+	next    atomic.Uint64 // Round robin over the workers for events without a key
+	queued  atomic.Int64  // Events waiting in the queues
+	busy    atomic.Int64  // Workers handling an event
+	full    atomic.Int64  // Events that found their queue full
+	dropped atomic.Int64  // Events dropped, because their queue was full or the service stopped
+
+	mu     sync.RWMutex // Held for writing while closing the queues, so handoffs never send on a closed queue
+	closed bool
+}
+
+// newWorkerPool creates a pool of size workers, each with a queue of queueSize events
+func newWorkerPool(size, queueSize int, policy string) *workerPool {
+	p := &workerPool{
+		queues: make([]chan func(), size),
+		policy: policy,
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan func(), queueSize)
+	}
+	return p
+}
+
+// start runs the workers until close is called and their queues are empty.
+// Jobs still queued once ctx is cancelled are dropped rather than run.
+func (p *workerPool) start(ctx context.Context) {
+	for _, queue := range p.queues {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for run := range queue {
+				p.queued.Add(-1)
+				if ctx.Err() != nil {
+					p.dropped.Add(1)
+					continue
+				}
+				p.busy.Add(1)
+				run()
+				p.busy.Add(-1)
+			}
+		}()
+	}
+}
+
+// submit queues run on the worker for key, or on the next worker when key is empty.
+// When the queue is full it blocks until there is room or ctx is cancelled, or drops the event,
+// depending on the policy. It reports whether the event was queued.
+func (p *workerPool) submit(ctx context.Context, key string, run func()) bool {
+	if p == nil {
+		run()
+		return true
+	}
+	return p.submitTo(ctx, p.worker(key), run)
+}
+
+// submitTo queues run on the given worker, like submit
+func (p *workerPool) submitTo(ctx context.Context, worker int, run func()) bool {
+	if p == nil {
+		run()
+		return true
+	}
+
+	queue := p.queues[worker]
+
+	p.queued.Add(1)
+	select {
+	case queue <- run:
+		return true
+	default:
+	}
+
+	p.full.Add(1)
+	if p.policy == QueueFullDrop {
+		p.queued.Add(-1)
+		p.dropped.Add(1)
+		return false
+	}
+
+	select {
+	case queue <- run:
+		return true
+	case <-ctx.Done():
+		p.queued.Add(-1)
+		p.dropped.Add(1)
+		return false
+	}
+}
+
+// handoff is called by a job running on worker from to queue run on the worker for key, behind
+// the events already queued for that key. It runs run inline when that is the same worker or
+// once the pool is closing. A worker never waits for room in another worker's queue, as two
+// workers handing off to each other could then wait forever: when the queue is full, run is
+// dropped under the drop policy and otherwise runs inline, out of order. It reports whether
+// run was queued or has run.
+func (p *workerPool) handoff(from int, key string, run func()) bool {
+	if p == nil {
+		run()
+		return true
+	}
+
+	to := p.worker(key)
+	p.mu.RLock()
+	if to == from || p.closed {
+		p.mu.RUnlock()
+		run()
+		return true
+	}
+
+	p.queued.Add(1)
+	select {
+	case p.queues[to] <- run:
+		p.mu.RUnlock()
+		return true
+	default:
+	}
+	p.mu.RUnlock()
+
+	p.queued.Add(-1)
+	p.full.Add(1)
+	if p.policy == QueueFullDrop {
+		p.dropped.Add(1)
+		return false
+	}
+	run()
+	return true
+}
+
+// worker returns the index of the worker handling key
+func (p *workerPool) worker(key string) int {
+	if p == nil {
+		return 0
+	}
+	if key == "" {
+		return int(p.next.Add(1) % uint64(len(p.queues)))
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+// close stops the workers once their queues are empty; no job may be submitted afterwards
+func (p *workerPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	for _, queue := range p.queues {
+		close(queue)
+	}
+}
+
+// wait waits for the workers to stop
+func (p *workerPool) wait() {
+	p.wg.Wait()
+}
+
+// startListener runs a listener, tracking it so the workers stop only once it has
+func (s *Service) startListener(ctx context.Context, listen func(context.Context)) {
+	s.wg.Add(1)
+	s.listening.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.listening.Done()
+		listen(ctx)
+	}()
+}
+
+// startWorkers runs the worker pool. Once every listener has stopped the workers finish the
+// queued events and stop; events still queued when the handlers' context is cancelled are dropped.
+func (s *Service) startWorkers() {
+	if s.workers == nil {
+		return
+	}
+	s.workers.start(s.handlerContext(context.Background()))
+	slog.Info("Started workers", "workers", len(s.workers.queues), "queue_size", cap(s.workers.queues[0]),
+		"queue_full_policy", s.workers.policy)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.listening.Wait()
+		s.workers.close()
+		s.workers.wait()
+	}()
+}
+
+// workerStats is the state of the worker pool as reported by /status
+type workerStats struct {
+	Size    int    `json:"size"`
+	Busy    int64  `json:"busy"`
+	Queued  int64  `json:"queued"`
+	Full    int64  `json:"queue_full"`
+	Dropped int64  `json:"dropped"`
+	Policy  string `json:"queue_full_policy"`
+}
+
+// stats returns the pool's current state
+func (p *workerPool) stats() workerStats {
+	return workerStats{
+		Size:    len(p.queues),
+		Busy:    p.busy.Load(),
+		Queued:  p.queued.Load(),
+		Full:    p.full.Load(),
+		Dropped: p.dropped.Load(),
+		Policy:  p.policy,
+	}
+}
+
+// Event keys decide which events are handled in order. Commands, Poppit output and block
+// actions name their project. Reactions only reveal the project once the reacted message is
+// fetched from Slack: they are fetched in order per message, then handed over to their project.
+
+// projectKey is the worker key for events about a project
+func projectKey(name string) string {
+	if name == "" {
+		return ""
+	}
+	return "project:" + name
+}
+
+// commandEventKey keys a /slack-compose command by the project it names
+func commandEventKey(payload string) string {
+	var cmd SlackCommand
+	if err := json.Unmarshal([]byte(payload), &cmd); err != nil {
+		return ""
+	}
+	parsed, err := parseCommandText(cmd.Text)
+	if err != nil || parsed.Kind != commandRun {
+		return ""
+	}
+	return projectKey(parsed.Project)
+}
+
+// poppitOutputEventKey keys command output by the project in its metadata
+func poppitOutputEventKey(payload string) string {
+	var output PoppitCommandOutput
+	if err := json.Unmarshal([]byte(payload), &output); err != nil {
+		return ""
+	}
+	project, _ := output.Metadata["project"].(string)
+	return projectKey(project)
+}
+
+// reactionEventKey keys a reaction by the message it was added to, until the message is fetched
+func reactionEventKey(payload string) string {
+	var reaction SlackReaction
+	if err := json.Unmarshal([]byte(payload), &reaction); err != nil || reaction.Event.Item.TS == "" {
+		return ""
+	}
+	return "message:" + reaction.Event.Item.Channel + "/" + reaction.Event.Item.TS
+}
+
+// blockActionEventKey keys a block action by the project selected in the dialog
+func blockActionEventKey(payload string) string {
+	var action SlackBlockAction
+	if err := json.Unmarshal([]byte(payload), &action); err != nil {
+		return ""
+	}
+	return projectKey(selectedProject(action))
+}
+
+// logDroppedEvent records an event that could not be queued
+func (s *Service) logDroppedEvent(name, key string) {
+	slog.Warn("Worker queue full, dropping event", "listener", name, "key", key, "policy", s.config.WorkerQueueFullPolicy)
+	s.metrics.eventIgnored(IgnoreReasonQueueFull)
+}
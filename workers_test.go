@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestWorkerPool_OrdersEventsPerKey(t *testing.T) {
+	pool := newWorkerPool(4, 10, QueueFullBlock)
+	pool.start(context.Background())
+
+	var mu sync.Mutex
+	handled := make(map[string][]int)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("project:%d", i%3)
+		pool.submit(context.Background(), key, func() {
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			handled[key] = append(handled[key], i)
+			mu.Unlock()
+		})
+	}
+	pool.close()
+	pool.wait()
+
+	for key, order := range handled {
+		for j := 1; j < len(order); j++ {
+			if order[j] < order[j-1] {
+				t.Fatalf("%s handled out of order: %v", key, order)
+			}
+		}
+	}
+	if len(handled) != 3 {
+		t.Errorf("handled %d keys, want 3", len(handled))
+	}
+}
+
+// keysOnDifferentWorkers returns two project keys handled by different workers of the pool
+func keysOnDifferentWorkers(t *testing.T, pool *workerPool) (string, string) {
+	t.Helper()
+	first := projectKey("a")
+	for i := 0; i < 100; i++ {
+		if key := projectKey(fmt.Sprintf("b%d", i)); pool.worker(key) != pool.worker(first) {
+			return first, key
+		}
+	}
+	t.Fatal("no key found on another worker")
+	return "", ""
+}
+
+func TestWorkerPool_RunsKeysInParallel(t *testing.T) {
+	pool := newWorkerPool(2, 10, QueueFullBlock)
+	pool.start(context.Background())
+	defer pool.close()
+	slow, fast := keysOnDifferentWorkers(t, pool)
+
+	release := make(chan struct{})
+	defer close(release)
+	pool.submit(context.Background(), slow, func() { <-release })
+
+	done := make(chan struct{})
+	pool.submit(context.Background(), fast, func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("a slow project blocked another project's events")
+	}
+}
+
+// fullPool returns a single-worker pool whose worker is blocked and whose queue is full
+func fullPool(policy string) (*workerPool, chan struct{}) {
+	pool := newWorkerPool(1, 1, policy)
+	pool.start(context.Background())
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.submit(context.Background(), "", func() {
+		close(started)
+		<-release
+	})
+	<-started
+	pool.submit(context.Background(), "", func() {})
+	return pool, release
+}
+
+func TestWorkerPool_DropPolicy(t *testing.T) {
+	pool, release := fullPool(QueueFullDrop)
+	defer close(release)
+
+	if pool.submit(context.Background(), "", func() {}) {
+		t.Error("submit to a full queue succeeded with the drop policy")
+	}
+	if stats := pool.stats(); stats.Full != 1 || stats.Dropped != 1 || stats.Queued != 1 || stats.Busy != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestWorkerPool_BlockPolicy(t *testing.T) {
+	pool, release := fullPool(QueueFullBlock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if pool.submit(ctx, "", func() {}) {
+		t.Error("submit to a full queue succeeded before there was room")
+	}
+
+	queued := make(chan bool)
+	go func() { queued <- pool.submit(context.Background(), "", func() {}) }()
+	close(release)
+	if !<-queued {
+		t.Error("blocked submit failed once there was room")
+	}
+	pool.close()
+	pool.wait()
+
+	if stats := pool.stats(); stats.Dropped != 1 || stats.Queued != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestWorkerPool_DropsQueuedEventsOnceCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := newWorkerPool(1, 10, QueueFullBlock)
+	pool.start(ctx)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	ran := 0
+	pool.submit(context.Background(), "", func() {
+		close(started)
+		<-release
+	})
+	pool.submit(context.Background(), "", func() { ran++ })
+	<-started
+	cancel()
+	close(release)
+	pool.close()
+	pool.wait()
+
+	if ran != 0 || pool.stats().Dropped != 1 {
+		t.Errorf("ran = %d, stats = %+v; want the queued event dropped", ran, pool.stats())
+	}
+}
+
+func TestWorkerPool_HandoffQueuesBehindTheKeysEvents(t *testing.T) {
+	pool := newWorkerPool(2, 10, QueueFullBlock)
+	pool.start(context.Background())
+	project, message := keysOnDifferentWorkers(t, pool)
+
+	var mu sync.Mutex
+	var order []string
+	record := func(event string) func() {
+		return func() {
+			mu.Lock()
+			order = append(order, event)
+			mu.Unlock()
+		}
+	}
+
+	release := make(chan struct{})
+	pool.submit(context.Background(), project, func() { <-release })
+	pool.submit(context.Background(), project, record("command 1"))
+
+	handedOff := make(chan struct{})
+	pool.submit(context.Background(), message, func() {
+		pool.handoff(pool.worker(message), project, record("reaction"))
+		close(handedOff)
+	})
+	<-handedOff
+	pool.submit(context.Background(), project, record("command 2"))
+	close(release)
+	pool.close()
+	pool.wait()
+
+	if fmt.Sprint(order) != "[command 1 reaction command 2]" {
+		t.Errorf("order = %v, want [command 1 reaction command 2]", order)
+	}
+}
+
+func TestWorkerPool_HandoffNeverWaits(t *testing.T) {
+	tests := []struct {
+		policy  string
+		want    bool
+		wantRan bool
+	}{
+		{QueueFullBlock, true, true},
+		{QueueFullDrop, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			pool := newWorkerPool(2, 1, tt.policy)
+			pool.start(context.Background())
+			full, other := keysOnDifferentWorkers(t, pool)
+
+			release := make(chan struct{})
+			started := make(chan struct{})
+			pool.submit(context.Background(), full, func() {
+				close(started)
+				<-release
+			})
+			<-started
+			pool.submit(context.Background(), full, func() {})
+
+			ran := false
+			if got := pool.handoff(pool.worker(other), full, func() { ran = true }); got != tt.want || ran != tt.wantRan {
+				t.Errorf("handoff = %v, ran = %v; want %v, %v", got, ran, tt.want, tt.wantRan)
+			}
+			if stats := pool.stats(); stats.Full != 1 || stats.Queued != 1 {
+				t.Errorf("stats = %+v", stats)
+			}
+			close(release)
+			pool.close()
+			pool.wait()
+		})
+	}
+}
+
+func TestWorkerPool_HandoffAfterCloseRunsInline(t *testing.T) {
+	pool := newWorkerPool(2, 10, QueueFullBlock)
+	pool.start(context.Background())
+	pool.close()
+
+	ran := false
+	if !pool.handoff(0, projectKey("a"), func() { ran = true }) || !ran {
+		t.Error("handoff on a closed pool did not run the job")
+	}
+	pool.wait()
+}
+
+func TestWorkerPool_NilRunsInline(t *testing.T) {
+	var pool *workerPool
+	ran := false
+	if !pool.submit(context.Background(), "", func() { ran = true }) || !ran {
+		t.Error("nil pool did not run the job")
+	}
+}
+
+func TestEventKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     func(string) string
+		payload string
+		want    string
+	}{
+		{"command", commandEventKey, `{"command":"/slack-compose","text":"my-project logs web"}`, "project:my-project"},
+		{"command dialog", commandEventKey, `{"command":"/slack-compose","text":""}`, ""},
+		{"command list", commandEventKey, `{"command":"/slack-compose","text":"list"}`, ""},
+		{"poppit output", poppitOutputEventKey, `{"metadata":{"project":"my-project"}}`, "project:my-project"},
+		{"reaction message", reactionEventKey, `{"event":{"item":{"channel":"C1","ts":"1.2"}}}`, "message:C1/1.2"},
+		{"block action", blockActionEventKey, `{"state":{"values":{"project_block":{"SlackCompose":{"selected_option":{"value":"my-project"}}}}}}`, "project:my-project"},
+		{"invalid", reactionEventKey, `not json`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.key(tt.payload); got != tt.want {
+				t.Errorf("key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteReaction_KeysByProject(t *testing.T) {
+	tests := []struct {
+		name      string
+		emoji     string
+		eventType string
+		payload   map[string]interface{}
+	}{
+		{"action", EmojiArrowsCounterClockwise, "slack-compose", map[string]interface{}{"project": "my-project"}},
+		{"confirmation", EmojiWhiteCheckMark, EventTypeConfirmation, map[string]interface{}{"project": "my-project", "confirmation_id": "c1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &mockSlackClient{message: &SlackMessage{Metadata: SlackMetadata{EventType: tt.eventType, EventPayload: tt.payload}}}
+			svc := newTestService(&mockRedisClient{}, sc)
+			svc.pending = map[string]pendingConfirmation{"c1": {ExpiresAt: time.Now().Add(time.Minute)}}
+
+			key, next := svc.routeReaction(context.Background(), confirmReaction("U1", tt.emoji))
+			if key != "project:my-project" || next == nil {
+				t.Errorf("routeReaction = %q, %v; want the project's key and the rest of the handling", key, next != nil)
+			}
+		})
+	}
+
+	svc := newTestService(&mockRedisClient{}, &mockSlackClient{})
+	if _, next := svc.routeReaction(context.Background(), confirmReaction("U1", "thumbsup")); next != nil {
+		t.Error("unsupported reaction was not ignored")
+	}
+}
+
+func TestStart_HandlesEventsOnWorkers(t *testing.T) {
+	messages := make(chan *redis.Message, 1)
+	messages <- &redis.Message{Payload: "event"}
+	rc := &mockRedisClient{subscriptions: []*mockPubSub{{messages: messages}}}
+	svc := newTestService(rc, nil)
+	svc.config.RedisTransport = TransportPubSub
+	svc.workers = newWorkerPool(2, 10, QueueFullBlock)
+	svc.metrics = newMetrics()
+	svc.metrics.observeWorkers(svc.workers)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc.beginWork(ctx)
+
+	handled := make(chan string, 1)
+	svc.startListener(ctx, func(ctx context.Context) {
+		svc.listen(ctx, "commands", "slack-commands", func(ctx context.Context, payload string) { handled <- payload }, nil)
+	})
+	svc.startWorkers()
+
+	select {
+	case payload := <-handled:
+		if payload != "event" {
+			t.Errorf("handled %q, want event", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event was not handled")
+	}
+	cancel()
+
+	if summary := svc.Shutdown(time.Second); !summary.Drained {
+		t.Errorf("summary = %+v, want drained", summary)
+	}
+	if body := getHealth(svc, "/metrics").Body.String(); !strings.Contains(body, "slackcompose_workers 2") {
+		t.Errorf("/metrics does not report the pool size:\n%s", body)
+	}
+}